@@ -0,0 +1,31 @@
+package main
+
+import (
+	"ac-ai/internal/config"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// runConfig - проверка конфигурации без запуска сервера:
+//
+//	server config print     # эффективные значения после всех слоев, секреты замаскированы
+//
+// Печатает и невалидную конфигурацию: видно, откуда взялось неверное значение
+func runConfig(args []string) error {
+	if len(args) == 0 || args[0] != "print" {
+		return errors.New("usage: config print")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Print(os.Stdout); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr)
+		return err
+	}
+	return nil
+}
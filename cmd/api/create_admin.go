@@ -0,0 +1,70 @@
+package main
+
+import (
+	"ac-ai/internal/auth"
+	"ac-ai/internal/models"
+	"ac-ai/internal/repository"
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// runCreateAdmin - bootstrap первого администратора:
+//
+//	server create-admin -email admin@bank.kz
+//
+// Пароль читается из ADMIN_PASSWORD или из stdin, чтобы не светиться в истории shell и в ps
+func runCreateAdmin(db *gorm.DB, args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	email := fs.String("email", "", "email администратора")
+	force := fs.Bool("force", false, "создать, даже если активный администратор уже есть")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	userRepo := repository.NewUserRepository(db)
+
+	// Дальнейших администраторов приглашают через API
+	count, err := userRepo.CountActiveByRole(models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if count > 0 && !*force {
+		return errors.New("an active admin already exists, invite new admins via /api/v1/admin/staff/invite (or pass -force)")
+	}
+
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		fmt.Print("Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("failed to read password: %w", err)
+		}
+		password = strings.TrimSpace(line)
+	}
+	if err := auth.CheckPasswordPolicy(password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	user, err := userRepo.CreateActiveStaffUser(*email, hashedPassword, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Admin created", slog.String("email", user.Email), slog.Uint64("user_id", uint64(user.ID)))
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runGenerateJWTKey - создает новый ключ подписи <dir>/<kid>.pem.
+// Ротация: сгенерировать ключ, раскатить его (JWKS покажет его заранее),
+// затем переключить JWT_ACTIVE_KID. Старый файл удалить после JWT_KEY_GRACE_HOURS
+func runGenerateJWTKey(args []string) error {
+	fs := flag.NewFlagSet("generate-jwt-key", flag.ContinueOnError)
+	dir := fs.String("dir", "./keys", "directory for PEM files")
+	alg := fs.String("alg", "EdDSA", "key algorithm: EdDSA or RS256")
+	kid := fs.String("kid", "", "key id (default: current date and time)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *kid == "" {
+		*kid = time.Now().UTC().Format("20060102-150405")
+	}
+
+	var der []byte
+	var err error
+	switch *alg {
+	case "EdDSA":
+		var key ed25519.PrivateKey
+		if _, key, err = ed25519.GenerateKey(rand.Reader); err == nil {
+			der, err = x509.MarshalPKCS8PrivateKey(key)
+		}
+	case "RS256":
+		var key *rsa.PrivateKey
+		if key, err = rsa.GenerateKey(rand.Reader, 3072); err == nil {
+			der, err = x509.MarshalPKCS8PrivateKey(key)
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm %q", *alg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		return err
+	}
+	path := filepath.Join(*dir, *kid+".pem")
+	if _, err := os.Stat(path); err == nil {
+		return errors.New("key file already exists: " + path)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return err
+	}
+
+	fmt.Printf("Key written to %s (kid %s)\n", path, *kid)
+	return nil
+}
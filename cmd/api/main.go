@@ -2,30 +2,198 @@ package main
 
 import (
 	"ac-ai/internal/api"
+	"ac-ai/internal/auth"
 	"ac-ai/internal/config"
 	"ac-ai/internal/database"
+	"ac-ai/internal/events"
+	"ac-ai/internal/lockout"
+	"ac-ai/internal/logging"
+	"ac-ai/internal/metrics"
+	"ac-ai/internal/repository"
+	"ac-ai/internal/services"
+	"ac-ai/internal/tracing"
+	"context"
 	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
+	// Конфигурацию печатаем до проверки, иначе невалидную не посмотреть: server config print
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfig(os.Args[2:]); err != nil {
+			log.Fatalf("Config: %v", err)
+		}
+		return
+	}
+
 	// 1. Загрузка конфигурации
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Could not load config: %v", err)
 	}
+
+	// Структурированные логи; log.Printf/Fatalf тоже идут через этот обработчик
+	logOpts, _ := cfg.LoggingOptions()
+	slog.SetDefault(logging.New(os.Stderr, logOpts))
+	slog.Info("Loaded config", slog.String("env", cfg.Env))
 	
+	// Генерация ключа подписи JWT не требует БД: server generate-jwt-key -dir ./keys
+	if len(os.Args) > 1 && os.Args[1] == "generate-jwt-key" {
+		if err := runGenerateJWTKey(os.Args[2:]); err != nil {
+			log.Fatalf("Could not generate JWT key: %v", err)
+		}
+		return
+	}
+
+	// Миграции схемы: server migrate up|down|status|create
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	// Параметры хэширования и парольная политика нужны и командам (create-admin)
+	if err := auth.ConfigurePasswords(cfg); err != nil {
+		log.Fatalf("Invalid password settings: %v", err)
+	}
+
+	// Ключи подписи JWT (ошибку в PEM лучше увидеть до старта)
+	keys, err := auth.LoadKeySet(cfg)
+	if err != nil {
+		log.Fatalf("Could not load JWT keys: %v", err)
+	}
+
 	// 2. Подключение к БД
 	db, err := database.InitDB(cfg)
 	if err != nil {
 		log.Fatalf("Could not connect to database: %v", err)
 	}
 
-	// 3. Настройка роутера
-	router := api.SetupRouter(db, cfg)
+	// Схема ведётся миграциями; со старой схемой не стартуем
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Could not load migrations: %v", err)
+	}
+	if err := migrator.CheckCurrent(context.Background()); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
+
+	// Служебные команды: server create-admin -email ...
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		if err := runCreateAdmin(db, os.Args[2:]); err != nil {
+			log.Fatalf("Could not create admin: %v", err)
+		}
+		return
+	}
+
+	// SIGTERM/SIGINT отменяет ctx: фоновые задачи и SSE-потоки завершаются,
+	// а текущие запросы (в том числе ожидающие OpenAI) дорабатывают
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Трассировка: без OTEL_EXPORTER_OTLP_ENDPOINT спаны создаются, но не записываются
+	tracerOpts := tracing.Options{ServiceName: cfg.TracingServiceName, SampleRatio: cfg.TracingSampleRatio}
+	if cfg.OTLPEndpoint != "" {
+		exporter, err := tracing.NewOTLPExporter(ctx, cfg.OTLPEndpoint, cfg.OTLPHeaders)
+		if err != nil {
+			log.Fatalf("Failed to create trace exporter: %v", err)
+		}
+		tracerOpts.Exporter = exporter
+		slog.Info("Exporting traces", slog.String("endpoint", cfg.OTLPEndpoint))
+	}
+	tracer := tracing.Setup(tracerOpts)
+
+	// 3. Шина событий для real-time обновлений очереди агентов
+	var bus events.Bus
+	switch cfg.EventBusBackend {
+	case "postgres":
+		bus = events.NewPostgresBus(ctx, db, cfg.DatabaseURL)
+	default:
+		bus = events.NewMemoryBus()
+	}
+
+	// 4. Фоновые задачи
+	appRepo := repository.NewApplicationRepository(db, bus)
+	services.StartInfoRequestExpiry(ctx, appRepo, time.Minute)
+	// Счетчики входов в памяти чистит сам MemoryStore
+	var throttles *lockout.PostgresStore
+	if cfg.LoginLockoutStore == "postgres" {
+		throttles = lockout.NewPostgresStore(db)
+	}
+	loginWindow := time.Duration(cfg.LoginFailureWindowMinutes) * time.Minute
+	services.StartTokenCleanup(ctx, repository.NewTokenRepository(db), throttles, loginWindow, time.Hour)
+
+	// Метрики, которые считаются при опросе /metrics
+	if sqlDB, err := db.DB(); err == nil {
+		metrics.RegisterDBStats(sqlDB, "ac_ai")
+	}
+	metrics.RegisterReviewQueueDepth(func() (int64, error) {
+		countCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		return appRepo.CountReviewQueue(countCtx)
+	})
+
+	// 5. Настройка роутера
+	router := api.SetupRouter(ctx, db, cfg, bus, keys, migrator)
+
+	// 6. Запуск сервера
+	srv := &http.Server{
+		Addr:              ":" + cfg.ServerPort,
+		Handler:           router,
+		ReadTimeout:       time.Duration(cfg.ServerReadTimeoutSeconds) * time.Second,
+		ReadHeaderTimeout: 10 * time.Second,
+		WriteTimeout:      time.Duration(cfg.ServerWriteTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(cfg.ServerIdleTimeoutSeconds) * time.Second,
+	}
 
-	// 4. Запуск сервера
-	log.Printf("Starting server on port %s...", cfg.ServerPort)
-	if err := router.Run(":" + cfg.ServerPort); err != nil {
+	serveErr := make(chan error, 2)
+	go func() {
+		slog.Info("Starting server", slog.String("port", cfg.ServerPort))
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	// /metrics на отдельном порту, недоступном снаружи кластера
+	var metricsSrv *http.Server
+	if cfg.MetricsPort != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		metricsSrv = &http.Server{Addr: ":" + cfg.MetricsPort, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			slog.Info("Serving metrics", slog.String("port", cfg.MetricsPort))
+			serveErr <- metricsSrv.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err := <-serveErr:
 		log.Fatalf("Could not start server: %v", err)
+	case <-ctx.Done():
+	}
+	stop() // Повторный сигнал завершает процесс сразу
+
+	// 7. Остановка: новые соединения не принимаем, текущие запросы дорабатывают
+	timeout := time.Duration(cfg.ServerShutdownTimeoutSeconds) * time.Second
+	slog.Info("Shutting down, waiting for in-flight requests", slog.Duration("timeout", timeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Graceful shutdown timed out", slog.Any("error", err))
+	}
+	if metricsSrv != nil {
+		metricsSrv.Shutdown(shutdownCtx)
+	}
+	if err := tracer.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Failed to flush traces", slog.Any("error", err))
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
 	}
+	slog.Info("Server stopped")
 }
\ No newline at end of file
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"ac-ai/internal/config"
+	"ac-ai/internal/database"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// runMigrate - управление схемой БД:
+//
+//	server migrate up
+//	server migrate down [-steps 1]
+//	server migrate status
+//	server migrate create -name add_currency [-dir internal/database/migrations]
+//
+// create только пишет файлы и к БД не подключается
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down|status|create")
+	}
+	cmd, args := args[0], args[1:]
+
+	if cmd == "create" {
+		fs := flag.NewFlagSet("migrate create", flag.ContinueOnError)
+		name := fs.String("name", "", "имя миграции (a-z, 0-9, _)")
+		dir := fs.String("dir", "internal/database/migrations", "каталог миграций")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *name == "" {
+			return errors.New("-name is required")
+		}
+		up, down, err := database.CreateMigration(*dir, *name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Created %s\nCreated %s\n", up, down)
+		return nil
+	}
+
+	db, err := database.InitDB(cfg)
+	if err != nil {
+		return err
+	}
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			slog.Info("Applied migration", slog.Int64("version", m.Version), slog.String("name", m.Name))
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			slog.Info("Schema is up to date")
+		}
+		return nil
+
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := fs.Int("steps", 1, "сколько последних миграций откатить")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *steps < 1 {
+			return errors.New("-steps must be positive")
+		}
+		reverted, err := migrator.Down(*steps)
+		for _, m := range reverted {
+			slog.Info("Reverted migration", slog.Int64("version", m.Version), slog.String("name", m.Name))
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown migrate command %q", cmd)
+}
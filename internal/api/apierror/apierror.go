@@ -0,0 +1,125 @@
+// internal/api/apierror/apierror.go
+package apierror
+
+import (
+	"errors"
+	"maps"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Code - стабильный машиночитаемый код ошибки. Клиенты ветвятся по нему,
+// а не по тексту сообщения, поэтому коды не переименовываются
+type Code string
+
+// APIError - ошибка, которую можно показать клиенту. Причина (cause) только
+// логируется и в ответ не попадает
+type APIError struct {
+	Status  int
+	Code    Code
+	Message string         // текст по умолчанию (английский); переводы - в catalog.go
+	Fields  []FieldError   // ошибки отдельных полей запроса
+	Details map[string]any // безопасные подробности: retry_after, permission...
+	cause   error
+}
+
+func (e *APIError) Error() string {
+	if e.cause != nil {
+		return string(e.Code) + ": " + e.cause.Error()
+	}
+	return string(e.Code) + ": " + e.Message
+}
+
+func (e *APIError) Unwrap() error { return e.cause }
+
+// Is - ошибки равны по коду, чтобы errors.Is(err, apierror.ErrNotFound)
+// работал и для копий, созданных Wrap/With
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap - копия с внутренней причиной для лога
+func (e *APIError) Wrap(cause error) *APIError {
+	out := e.clone()
+	out.cause = cause
+	return out
+}
+
+// With - копия с дополнительным полем details
+func (e *APIError) With(key string, value any) *APIError {
+	out := e.clone()
+	if out.Details == nil {
+		out.Details = map[string]any{}
+	}
+	out.Details[key] = value
+	return out
+}
+
+func (e *APIError) clone() *APIError {
+	out := *e
+	out.Details = maps.Clone(e.Details)
+	return &out
+}
+
+// Internal - внутренняя ошибка: клиент получает INTERNAL_ERROR, а op и err
+// попадают в лог (ErrorMiddleware)
+func Internal(op string, err error) *APIError {
+	if err == nil {
+		err = errors.New(op)
+	} else {
+		err = &opError{op: op, err: err}
+	}
+	return ErrInternal.Wrap(err)
+}
+
+type opError struct {
+	op  string
+	err error
+}
+
+func (e *opError) Error() string { return e.op + ": " + e.err.Error() }
+func (e *opError) Unwrap() error { return e.err }
+
+// Abort - прерывает обработку запроса; ответ пишет ErrorMiddleware
+func Abort(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// From - APIError из произвольной ошибки; все неизвестное считается внутренней ошибкой
+func From(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return ErrInternal.Wrap(err)
+}
+
+// Response - тело ответа с ошибкой:
+// {"error": {"code": "...", "message": "...", "fields": [...], "request_id": "..."}}
+type Response struct {
+	Error Body `json:"error"`
+}
+
+type Body struct {
+	Code      Code                `json:"code"`
+	Message   string              `json:"message"`
+	Fields    []FieldErrorMessage `json:"fields,omitempty"`
+	Details   map[string]any      `json:"details,omitempty"`
+	RequestID string              `json:"request_id,omitempty"`
+}
+
+// Render - тело ответа на языке lang
+func (e *APIError) Render(lang, requestID string) Response {
+	body := Body{
+		Code:      e.Code,
+		Message:   localize(e.Code, lang, e.Message),
+		Details:   e.Details,
+		RequestID: requestID,
+	}
+	for _, f := range e.Fields {
+		body.Fields = append(body.Fields, f.render(lang))
+	}
+	return Response{Error: body}
+}
@@ -0,0 +1,127 @@
+// internal/api/apierror/catalog.go
+package apierror
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Языки сообщений. Первый - язык по умолчанию
+var languages = []string{"en", "ru"}
+
+// messages[code][lang] - переводы сообщений; английский текст хранится в самой ошибке
+var messages = map[Code]map[string]string{}
+
+func define(status int, code Code, en, ru string) *APIError {
+	if _, dup := messages[code]; dup {
+		panic("apierror: duplicate code " + string(code))
+	}
+	messages[code] = map[string]string{"en": en, "ru": ru}
+	return &APIError{Status: status, Code: code, Message: en}
+}
+
+// Общие ошибки
+var (
+	ErrBadRequest   = define(http.StatusBadRequest, "BAD_REQUEST", "Bad request", "Некорректный запрос")
+	ErrValidation   = define(http.StatusBadRequest, "VALIDATION_FAILED", "Request validation failed", "Проверьте правильность заполнения полей")
+	ErrMalformed    = define(http.StatusBadRequest, "MALFORMED_BODY", "Request body is malformed", "Тело запроса имеет неверный формат")
+	ErrInvalidID    = define(http.StatusBadRequest, "INVALID_ID", "Invalid id", "Некорректный идентификатор")
+	ErrNotFound     = define(http.StatusNotFound, "NOT_FOUND", "Resource not found", "Ресурс не найден")
+	ErrInternal     = define(http.StatusInternalServerError, "INTERNAL_ERROR", "Internal server error", "Внутренняя ошибка сервера")
+	ErrUnavailable  = define(http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Service is temporarily unavailable", "Сервис временно недоступен")
+	ErrRateLimited  = define(http.StatusTooManyRequests, "RATE_LIMITED", "API key rate limit exceeded", "Превышен лимит запросов для API-ключа")
+	ErrForbidden    = define(http.StatusForbidden, "FORBIDDEN", "Access forbidden", "Доступ запрещен")
+	ErrPermission   = define(http.StatusForbidden, "PERMISSION_DENIED", "Access forbidden: missing permission", "Доступ запрещен: недостаточно прав")
+	ErrAPIKeyScope  = define(http.StatusForbidden, "API_KEY_SCOPE_MISSING", "Access forbidden: API key is missing scope", "Доступ запрещен: у API-ключа нет нужной области доступа")
+	ErrDateRange    = define(http.StatusBadRequest, "INVALID_DATE_RANGE", "Invalid date range, expected YYYY-MM-DD with 'from' before 'to'", "Некорректный период: ожидается YYYY-MM-DD, 'from' раньше 'to'")
+	ErrExportFormat = define(http.StatusBadRequest, "UNSUPPORTED_EXPORT_FORMAT", "Unsupported export format", "Неподдерживаемый формат выгрузки")
+)
+
+// Аутентификация
+var (
+	ErrAuthRequired        = define(http.StatusUnauthorized, "AUTH_REQUIRED", "Authorization header required", "Требуется авторизация")
+	ErrAuthHeader          = define(http.StatusUnauthorized, "INVALID_AUTH_HEADER", "Invalid Bearer token format", "Неверный формат заголовка Authorization")
+	ErrTokenInvalid        = define(http.StatusUnauthorized, "TOKEN_INVALID", "Invalid or expired token", "Токен недействителен или истек")
+	ErrTokenRevoked        = define(http.StatusUnauthorized, "TOKEN_REVOKED", "Token has been revoked", "Токен отозван")
+	ErrRefreshInvalid      = define(http.StatusUnauthorized, "REFRESH_TOKEN_INVALID", "Invalid or expired refresh token", "Refresh-токен недействителен или истек")
+	ErrRefreshReused       = define(http.StatusUnauthorized, "REFRESH_TOKEN_REUSED", "Refresh token reuse detected, please log in again", "Refresh-токен использован повторно, войдите заново")
+	ErrMFATokenInvalid     = define(http.StatusUnauthorized, "MFA_TOKEN_INVALID", "Invalid or expired MFA token", "MFA-токен недействителен или истек")
+	ErrInvalidCredentials  = define(http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid credentials", "Неверный email или пароль")
+	ErrInvalidMFACode      = define(http.StatusUnauthorized, "INVALID_MFA_CODE", "Invalid code", "Неверный код")
+	ErrLoginLocked         = define(http.StatusTooManyRequests, "LOGIN_LOCKED", "Too many failed login attempts, try again later", "Слишком много неудачных попыток входа, попробуйте позже")
+	ErrAccountInactive     = define(http.StatusForbidden, "ACCOUNT_INACTIVE", "Account is not active", "Учетная запись не активна")
+	ErrActionTokenInvalid  = define(http.StatusBadRequest, "ACTION_TOKEN_INVALID", "Invalid or expired token", "Ссылка недействительна или устарела")
+	ErrInviteInvalid       = define(http.StatusBadRequest, "INVITE_INVALID", "Invite is invalid or expired", "Приглашение недействительно или истекло")
+	ErrInviteNotAccepted   = define(http.StatusConflict, "INVITE_NOT_ACCEPTED", "User has not accepted the invite yet", "Пользователь еще не принял приглашение")
+	ErrEmailTaken          = define(http.StatusConflict, "EMAIL_ALREADY_REGISTERED", "Email already registered", "Этот email уже зарегистрирован")
+	ErrEmailVerified       = define(http.StatusConflict, "EMAIL_ALREADY_VERIFIED", "Email is already verified", "Email уже подтвержден")
+	ErrTOTPEnabled         = define(http.StatusConflict, "TOTP_ALREADY_ENABLED", "TOTP is already enabled", "Двухфакторная аутентификация уже включена")
+	ErrTOTPNotStarted      = define(http.StatusBadRequest, "TOTP_ENROLLMENT_NOT_STARTED", "Start enrollment first", "Сначала начните подключение двухфакторной аутентификации")
+	ErrAPIKeyRequired      = define(http.StatusUnauthorized, "API_KEY_REQUIRED", "API key required", "Требуется API-ключ")
+	ErrAPIKeyInvalid       = define(http.StatusUnauthorized, "API_KEY_INVALID", "Invalid or revoked API key", "API-ключ недействителен или отозван")
+	ErrIdentityUnavailable = define(http.StatusBadGateway, "IDP_UNAVAILABLE", "Identity provider is unavailable", "Сервис корпоративного входа недоступен")
+)
+
+// Пользователи и заявки
+var (
+	ErrUserNotFound          = define(http.StatusNotFound, "USER_NOT_FOUND", "User not found", "Пользователь не найден")
+	ErrProfileNotFound       = define(http.StatusNotFound, "PROFILE_NOT_FOUND", "User profile not found", "Профиль пользователя не найден")
+	ErrStaffNotFound         = define(http.StatusNotFound, "STAFF_NOT_FOUND", "Staff user not found", "Сотрудник не найден")
+	ErrNotClient             = define(http.StatusBadRequest, "NOT_A_CLIENT", "User is not a client or has no profile", "Пользователь не является клиентом или у него нет профиля")
+	ErrProfileRequired       = define(http.StatusBadRequest, "PROFILE_REQUIRED", "profile_data is required for CLIENT role", "Для клиента обязателен финансовый профиль (profile_data)")
+	ErrSelfModification      = define(http.StatusBadRequest, "SELF_MODIFICATION", "You cannot change your own account", "Нельзя изменить собственную учетную запись")
+	ErrApplicationNotPending = define(http.StatusConflict, "APPLICATION_NOT_PENDING", "Application is not pending manual review", "Заявка не ожидает ручного рассмотрения")
+	ErrApplicationClaimed    = define(http.StatusConflict, "APPLICATION_CLAIMED", "Application is claimed by another agent", "Заявку уже взял в работу другой агент")
+	ErrApplicationNotClaimed = define(http.StatusConflict, "APPLICATION_NOT_CLAIMED", "Application is not claimed by you", "Вы не брали эту заявку в работу")
+	ErrInfoRequestNotFound   = define(http.StatusNotFound, "INFO_REQUEST_NOT_FOUND", "Info request not found or no longer open", "Запрос информации не найден или уже закрыт")
+	ErrInfoRequestClosed     = define(http.StatusConflict, "INFO_REQUEST_CLOSED", "Info request is no longer open", "Запрос информации уже закрыт")
+	ErrAPIKeyNotFound        = define(http.StatusNotFound, "API_KEY_NOT_FOUND", "API key not found or already revoked", "API-ключ не найден или уже отозван")
+	ErrFXRateUnavailable     = define(http.StatusServiceUnavailable, "FX_RATE_UNAVAILABLE", "Exchange rate for the requested currency is not available", "Курс запрошенной валюты временно недоступен")
+	ErrAIUnavailable         = define(http.StatusServiceUnavailable, "AI_UNAVAILABLE", "AI analysis is temporarily unavailable", "AI-анализ временно недоступен, попробуйте позже")
+)
+
+// Документы
+var (
+	ErrDocumentNotFound     = define(http.StatusNotFound, "DOCUMENT_NOT_FOUND", "Document not found", "Документ не найден")
+	ErrDocumentFileNotFound = define(http.StatusNotFound, "DOCUMENT_FILE_NOT_FOUND", "Document file not found", "Файл документа не найден")
+	ErrDocumentNotPending   = define(http.StatusConflict, "DOCUMENT_NOT_PENDING", "Document is not pending review", "Документ не ожидает проверки")
+	ErrFileRequired         = define(http.StatusBadRequest, "FILE_REQUIRED", "File is required", "Приложите файл")
+	ErrFileUnreadable       = define(http.StatusBadRequest, "FILE_UNREADABLE", "Failed to read file", "Не удалось прочитать файл")
+	ErrTooManyAttachments   = define(http.StatusBadRequest, "TOO_MANY_ATTACHMENTS", "Too many attachments", "Слишком много вложений")
+	ErrFileTooLarge         = define(http.StatusRequestEntityTooLarge, "FILE_TOO_LARGE", "File is too large", "Файл слишком большой")
+	ErrFileType             = define(http.StatusUnsupportedMediaType, "UNSUPPORTED_FILE_TYPE", "Only PDF, JPEG and PNG documents are accepted", "Принимаются только документы PDF, JPEG и PNG")
+	ErrFileRejected         = define(http.StatusUnprocessableEntity, "FILE_REJECTED", "File was rejected by the virus scanner", "Файл отклонен антивирусной проверкой")
+)
+
+// Codes - все коды ошибок в алфавитном порядке (для документации API)
+func Codes() []Code {
+	return slices.Sorted(maps.Keys(messages))
+}
+
+// Language - язык ответа по заголовку Accept-Language ("ru-RU,ru;q=0.9,en;q=0.8").
+// Веса q не учитываем: браузеры перечисляют языки в порядке предпочтения
+func Language(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		for _, lang := range languages {
+			if primary == lang {
+				return lang
+			}
+		}
+		// Казахский интерфейс пока не переведен - ближе всего русский
+		if primary == "kk" {
+			return "ru"
+		}
+	}
+	return languages[0]
+}
+
+func localize(code Code, lang, fallback string) string {
+	if msg, ok := messages[code][lang]; ok {
+		return msg
+	}
+	return fallback
+}
@@ -0,0 +1,138 @@
+// internal/api/apierror/validation.go
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError - ошибка одного поля: путь в JSON (profile_data.income), правило
+// из тега binding и его параметр (min=8 -> rule "min", param "8")
+type FieldError struct {
+	Field string
+	Rule  string
+	Param string
+	Kind  reflect.Kind // тип поля: для строк min/max - это длина
+}
+
+type FieldErrorMessage struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// FromBind переводит ошибку ShouldBindJSON/ShouldBindQuery/ShouldBind в APIError.
+// Тексты validator и encoding/json содержат имена Go-структур - клиенту их не отдаем
+func FromBind(err error) *APIError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		out := ErrValidation.Wrap(err)
+		for _, fe := range validationErrs {
+			out.Fields = append(out.Fields, FieldError{
+				Field: fieldPath(fe.Namespace()),
+				Rule:  fe.Tag(),
+				Param: fe.Param(),
+				Kind:  fe.Kind(),
+			})
+		}
+		return out
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		out := ErrValidation.Wrap(err)
+		out.Fields = []FieldError{{Field: typeErr.Field, Rule: "type", Param: jsonType(typeErr.Type)}}
+		return out
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrMalformed.Wrap(err)
+	}
+	return ErrBadRequest.Wrap(err)
+}
+
+// fieldPath - "RegisterRequest.profile_data.income" -> "profile_data.income".
+// Имена берутся из тегов json/form (см. registerValidators)
+func fieldPath(namespace string) string {
+	if _, rest, ok := strings.Cut(namespace, "."); ok {
+		return rest
+	}
+	return namespace
+}
+
+func jsonType(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
+
+// Сообщения по правилам валидации; {param} заменяется параметром правила.
+// Ключ с суффиксом ".string" - вариант для строковых полей
+var ruleMessages = map[string]map[string]string{
+	"required":   {"en": "is required", "ru": "обязательное поле"},
+	"email":      {"en": "must be a valid email address", "ru": "некорректный email"},
+	"url":        {"en": "must be a valid URL", "ru": "некорректный URL"},
+	"min":        {"en": "must be at least {param}", "ru": "должно быть не меньше {param}"},
+	"min.string": {"en": "must be at least {param} characters long", "ru": "должно содержать не меньше {param} символов"},
+	"max":        {"en": "must be at most {param}", "ru": "должно быть не больше {param}"},
+	"max.string": {"en": "must be at most {param} characters long", "ru": "должно содержать не больше {param} символов"},
+	"len":        {"en": "must have length {param}", "ru": "должно иметь длину {param}"},
+	"gt":         {"en": "must be greater than {param}", "ru": "должно быть больше {param}"},
+	"gte":        {"en": "must be greater than or equal to {param}", "ru": "должно быть не меньше {param}"},
+	"lt":         {"en": "must be less than {param}", "ru": "должно быть меньше {param}"},
+	"lte":        {"en": "must be less than or equal to {param}", "ru": "должно быть не больше {param}"},
+	"oneof":      {"en": "must be one of: {param}", "ru": "допустимые значения: {param}"},
+	"numeric":    {"en": "must be numeric", "ru": "должно быть числом"},
+	"password":   {"en": "does not meet the password policy", "ru": "пароль не соответствует требованиям"},
+	"currency":   {"en": "must be a supported currency code (KZT, USD, RUB)", "ru": "поддерживаются валюты KZT, USD, RUB"},
+	"type":       {"en": "must be of type {param}", "ru": "неверный тип, ожидается {param}"},
+	"":           {"en": "is invalid", "ru": "некорректное значение"},
+}
+
+func (f FieldError) render(lang string) FieldErrorMessage {
+	key := f.Rule
+	if f.Kind == reflect.String {
+		if _, ok := ruleMessages[key+".string"]; ok {
+			key += ".string"
+		}
+	}
+	templates, ok := ruleMessages[key]
+	if !ok {
+		templates = ruleMessages[""]
+	}
+	msg, ok := templates[lang]
+	if !ok {
+		msg = templates[languages[0]]
+	}
+	param := f.Param
+	if f.Rule == "oneof" {
+		param = strings.ReplaceAll(param, " ", ", ")
+	}
+	return FieldErrorMessage{
+		Field:   f.Field,
+		Rule:    f.Rule,
+		Param:   f.Param,
+		Message: strings.ReplaceAll(msg, "{param}", param),
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/auth"
+	"ac-ai/internal/models"
+	"ac-ai/internal/repository"
+	"ac-ai/internal/schemas"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// POST /api/v1/auth/verify-email
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req schemas.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	claims, err := h.JWT.ValidateChallengeToken(req.Token, auth.PurposeEmailVerify)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrActionTokenInvalid)
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByID(claims.UserID)
+	if err != nil || !h.JWT.MatchesState(claims, user.Email) {
+		apierror.Abort(c, apierror.ErrActionTokenInvalid)
+		return
+	}
+
+	if err := h.UserRepo.MarkEmailVerified(user.ID, user.Email); err != nil {
+		if errors.Is(err, repository.ErrTokenAlreadyUsed) {
+			apierror.Abort(c, apierror.ErrEmailVerified)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to verify email", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified"})
+}
+
+// POST /api/v1/auth/verify-email/resend
+// Повторная отправка письма (например, если ссылка истекла)
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	user, err := h.UserRepo.GetUserByID(userID.(uint))
+	if err != nil {
+		apierror.Abort(c, apierror.ErrUserNotFound)
+		return
+	}
+	if user.EmailVerifiedAt != nil {
+		apierror.Abort(c, apierror.ErrEmailVerified)
+		return
+	}
+
+	if err := h.Emails.SendVerification(c.Request.Context(), user); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to send email", err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// POST /api/v1/auth/forgot-password
+// Ответ всегда одинаковый, чтобы по нему нельзя было проверить, зарегистрирован ли email
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req schemas.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByEmail(req.Email)
+	switch {
+	// Сотрудники с входом через IdP паролей не имеют - сброс обошел бы SSO
+	case err == nil && user.Status == models.UserStatusActive && user.OIDCSubject == nil:
+		// Письмо отправляем в фоне: иначе по времени ответа тоже видно, есть ли такой email
+		go func(ctx context.Context, user models.User) {
+			if err := h.Emails.SendPasswordReset(ctx, &user); err != nil {
+				slog.ErrorContext(ctx, "Failed to send password reset email", slog.Uint64("target_user_id", uint64(user.ID)), slog.Any("error", err))
+			}
+		}(context.WithoutCancel(c.Request.Context()), *user)
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		slog.ErrorContext(c.Request.Context(), "Failed to look up user for password reset", slog.Any("error", err))
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "If the email is registered, a password reset link has been sent"})
+}
+
+// POST /api/v1/auth/reset-password
+// Новый пароль по токену из письма. Все сессии пользователя завершаются
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req schemas.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	claims, err := h.JWT.ValidateChallengeToken(req.Token, auth.PurposePasswordReset)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrActionTokenInvalid)
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByID(claims.UserID)
+	if err != nil || !h.JWT.MatchesState(claims, user.PasswordHash) {
+		apierror.Abort(c, apierror.ErrActionTokenInvalid)
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.Password)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to hash password", err))
+		return
+	}
+
+	if err := h.UserRepo.ResetPassword(user.ID, user.PasswordHash, hashedPassword); err != nil {
+		if errors.Is(err, repository.ErrTokenAlreadyUsed) {
+			apierror.Abort(c, apierror.ErrActionTokenInvalid)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to reset password", err))
+		return
+	}
+
+	// Старый пароль мог утечь - завершаем все сессии и снимаем блокировку входа
+	if err := h.TokenRepo.RevokeUserTokens(user.ID); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to revoke sessions after password reset", slog.Uint64("target_user_id", uint64(user.ID)), slog.Any("error", err))
+	}
+	if err := h.Lockout.UnlockAccount(user.Email); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to reset login failures", slog.Uint64("target_user_id", uint64(user.ID)), slog.Any("error", err))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset"})
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/auth"
+	"ac-ai/internal/lockout"
+	"ac-ai/internal/models"
+	"ac-ai/internal/repository"
+	"ac-ai/internal/schemas"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Срок действия приглашения сотрудника
+const staffInviteTTL = 72 * time.Hour
+
+type AdminHandler struct {
+	UserRepo  *repository.UserRepository
+	TokenRepo *repository.TokenRepository
+	Lockout   *lockout.Guard
+	AuditRepo *repository.AuditRepository
+}
+
+func NewAdminHandler(
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.TokenRepository,
+	guard *lockout.Guard,
+	auditRepo *repository.AuditRepository,
+) *AdminHandler {
+	return &AdminHandler{
+		UserRepo:  userRepo,
+		TokenRepo: tokenRepo,
+		Lockout:   guard,
+		AuditRepo: auditRepo,
+	}
+}
+
+// GET /api/v1/admin/staff
+func (h *AdminHandler) ListStaff(c *gin.Context) {
+	var pagination schemas.PaginationQuery
+	if err := c.ShouldBindQuery(&pagination); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	result, err := h.UserRepo.GetStaffUsers(pagination)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch staff", err))
+		return
+	}
+
+	staffOut := []schemas.StaffUserOut{}
+	for _, user := range result.Users {
+		staffOut = append(staffOut, toStaffUserOut(&user))
+	}
+
+	totalPages, currentPage := repository.CalculateMeta(result.TotalItems, pagination.Page, pagination.Limit)
+
+	c.JSON(http.StatusOK, schemas.PaginatedResponse{
+		Data: staffOut,
+		Meta: schemas.PaginationMeta{
+			TotalItems:   result.TotalItems,
+			TotalPages:   totalPages,
+			CurrentPage:  currentPage,
+			ItemsPerPage: pagination.Limit,
+		},
+	})
+}
+
+// POST /api/v1/admin/staff/invite
+func (h *AdminHandler) InviteStaff(c *gin.Context) {
+	var req schemas.StaffInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	if _, err := h.UserRepo.GetUserByEmail(req.Email); err == nil {
+		apierror.Abort(c, apierror.ErrEmailTaken)
+		return
+	}
+
+	token, tokenHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create invite", err))
+		return
+	}
+
+	adminID, _ := c.Get("userID")
+	expiresAt := time.Now().Add(staffInviteTTL)
+
+	user, err := h.UserRepo.InviteStaffUser(req.Email, req.Role, adminID.(uint), tokenHash, expiresAt)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create invite", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, schemas.StaffInviteOut{
+		User:        toStaffUserOut(user),
+		InviteToken: token,
+		ExpiresAt:   expiresAt,
+	})
+}
+
+// POST /api/v1/admin/staff/:id/activate
+func (h *AdminHandler) ActivateStaff(c *gin.Context) {
+	// Приглашенный сотрудник активируется сам, когда задает пароль
+	if staffID, err := strconv.ParseUint(c.Param("id"), 10, 64); err == nil {
+		if user, err := h.UserRepo.GetUserByID(uint(staffID)); err == nil && user.Status == models.UserStatusInvited {
+			apierror.Abort(c, apierror.ErrInviteNotAccepted)
+			return
+		}
+	}
+	h.updateStaff(c, map[string]any{"status": models.UserStatusActive})
+}
+
+// POST /api/v1/admin/staff/:id/deactivate
+func (h *AdminHandler) DeactivateStaff(c *gin.Context) {
+	h.updateStaff(c, map[string]any{"status": models.UserStatusDeactivated})
+}
+
+// PUT /api/v1/admin/staff/:id/role
+func (h *AdminHandler) ChangeStaffRole(c *gin.Context) {
+	var req schemas.StaffRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+	h.updateStaff(c, map[string]any{"role": req.Role})
+}
+
+// updateStaff - общая часть смены статуса/роли.
+// После изменения отзываем все токены сотрудника: новые права действуют сразу,
+// а деактивированный сотрудник теряет доступ, не дожидаясь истечения access-токена
+func (h *AdminHandler) updateStaff(c *gin.Context, updates map[string]any) {
+	staffID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidID)
+		return
+	}
+
+	// Администратор не может заблокировать или понизить сам себя
+	adminID, _ := c.Get("userID")
+	if uint(staffID) == adminID.(uint) {
+		apierror.Abort(c, apierror.ErrSelfModification)
+		return
+	}
+
+	user, err := h.UserRepo.UpdateStaffUser(uint(staffID), updates)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Abort(c, apierror.ErrStaffNotFound)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to update staff user", err))
+		return
+	}
+
+	if err := h.TokenRepo.RevokeUserTokens(user.ID); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to revoke user sessions", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toStaffUserOut(user))
+}
+
+// POST /api/v1/admin/users/:id/unlock
+// Снимает блокировку входа после серии неудачных попыток (клиент или сотрудник)
+func (h *AdminHandler) UnlockLogin(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidID)
+		return
+	}
+
+	var req schemas.UnlockLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByID(uint(userID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Abort(c, apierror.ErrUserNotFound)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to look up user", err))
+		return
+	}
+
+	if err := h.Lockout.UnlockAccount(user.Email); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to unlock account", err))
+		return
+	}
+	if req.IP != "" {
+		if err := h.Lockout.UnlockIP(req.IP); err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to unlock IP", err))
+			return
+		}
+	}
+
+	adminID := c.GetUint("userID")
+	h.AuditRepo.Record(&models.AuditEvent{
+		Action:  models.AuditLoginUnlocked,
+		ActorID: &adminID,
+		Subject: lockout.AccountKey(user.Email),
+		IP:      req.IP,
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+func toStaffUserOut(user *models.User) schemas.StaffUserOut {
+	return schemas.StaffUserOut{
+		ID:        user.ID,
+		CreatedAt: user.CreatedAt,
+		Email:     user.Email,
+		Role:      user.Role,
+		Status:    user.Status,
+	}
+}
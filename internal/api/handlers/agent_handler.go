@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"ac-ai/internal/api/apierror"
 	"ac-ai/internal/models"
 	"ac-ai/internal/repository"
 	"ac-ai/internal/schemas"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,39 +31,21 @@ func (h *AgentHandler) GetApplicationsForReview(c *gin.Context) {
 	// 1. Парсим параметры пагинации из URL (?page=1&limit=10)
 	var pagination schemas.PaginationQuery
 	if err := c.ShouldBindQuery(&pagination); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
+		apierror.Abort(c, apierror.FromBind(err))
 		return
 	}
 
 	// 2. Получаем данные из репозитория
 	result, err := h.AppRepo.GetApplicationsForReview(pagination)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch applications"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch applications", err))
 		return
 	}
 
 	// 3. Конвертируем в DTO (Data Transfer Object)
 	var applicationsOut []schemas.ApplicationOut
 	for _, app := range result.Applications {
-		// Десериализуем InternalReasons из JSON-строки в []string
-		var reasons []string
-		if app.InternalReasons != "" {
-			// Игнорируем ошибку, если JSON невалидный, просто вернется пустой слайс
-			_ = json.Unmarshal([]byte(app.InternalReasons), &reasons)
-		}
-
-		applicationsOut = append(applicationsOut, schemas.ApplicationOut{
-			ID:              app.ID,
-			CreatedAt:       app.CreatedAt,
-			User:            schemas.ApplicationUserOut{ID: app.User.ID, Email: app.User.Email},
-			RequestedAmount: app.RequestedAmount,
-			FinalDecision:   app.FinalDecision,
-			ColdScore:       app.ColdScore,
-			AIResponse:      app.AIResponse,
-			AgentStatus:     app.AgentStatus,
-			AgentNotes:      app.AgentNotes,
-			InternalReasons: reasons, // <-- Передаем []string
-		})
+		applicationsOut = append(applicationsOut, toApplicationOut(&app))
 	}
 
 	// 4. Считаем мета-данные пагинации
@@ -81,13 +67,13 @@ func (h *AgentHandler) GetApplicationsForReview(c *gin.Context) {
 func (h *AgentHandler) GetAllClients(c *gin.Context) {
 	var pagination schemas.PaginationQuery
 	if err := c.ShouldBindQuery(&pagination); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
+		apierror.Abort(c, apierror.FromBind(err))
 		return
 	}
 
 	result, err := h.UserRepo.GetUsersByRole(models.RoleClient, pagination)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch clients"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch clients", err))
 		return
 	}
 
@@ -106,6 +92,7 @@ func (h *AgentHandler) GetAllClients(c *gin.Context) {
 				JobExperienceYears: user.FinancialProfile.JobExperienceYears,
 				Age:                user.FinancialProfile.Age,
 				IncomeProof:        user.FinancialProfile.IncomeProof,
+				Currency:           user.FinancialProfile.Currency,
 			},
 		})
 	}
@@ -126,34 +113,19 @@ func (h *AgentHandler) GetAllClients(c *gin.Context) {
 func (h *AgentHandler) GetAllApplications(c *gin.Context) {
 	var pagination schemas.PaginationQuery
 	if err := c.ShouldBindQuery(&pagination); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pagination parameters"})
+		apierror.Abort(c, apierror.FromBind(err))
 		return
 	}
 
 	result, err := h.AppRepo.GetAllApplications(pagination)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch all applications"})
+		apierror.Abort(c, apierror.Internal("Failed to fetch all applications", err))
 		return
 	}
 
 	var applicationsOut []schemas.ApplicationOut
 	for _, app := range result.Applications {
-		var reasons []string
-		if app.InternalReasons != "" {
-			_ = json.Unmarshal([]byte(app.InternalReasons), &reasons)
-		}
-		applicationsOut = append(applicationsOut, schemas.ApplicationOut{
-			ID:              app.ID,
-			CreatedAt:       app.CreatedAt,
-			User:            schemas.ApplicationUserOut{ID: app.User.ID, Email: app.User.Email},
-			RequestedAmount: app.RequestedAmount,
-			FinalDecision:   app.FinalDecision,
-			ColdScore:       app.ColdScore,
-			AIResponse:      app.AIResponse,
-			AgentStatus:     app.AgentStatus,
-			AgentNotes:      app.AgentNotes,
-			InternalReasons: reasons,
-		})
+		applicationsOut = append(applicationsOut, toApplicationOut(&app))
 	}
 
 	totalPages, currentPage := repository.CalculateMeta(result.TotalItems, pagination.Page, pagination.Limit)
@@ -167,4 +139,176 @@ func (h *AgentHandler) GetAllApplications(c *gin.Context) {
 			ItemsPerPage: pagination.Limit,
 		},
 	})
-}
\ No newline at end of file
+}
+
+// POST /api/v1/agent/applications/:id/decision
+func (h *AgentHandler) DecideApplication(c *gin.Context) {
+	appID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidID)
+		return
+	}
+
+	var req schemas.AgentDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	agentID, _ := c.Get("userID")
+
+	app, err := h.AppRepo.SetAgentDecision(uint(appID), agentID.(uint), req.Status, req.Notes)
+	if err != nil {
+		apierror.Abort(c, applicationError("Failed to save decision", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toApplicationOut(app))
+}
+
+// POST /api/v1/agent/applications/:id/claim
+// Агент берет заявку в работу: другие агенты не могут ее решить, пока закрепление
+// не снято или не истекло. Повторный вызов продлевает закрепление
+func (h *AgentHandler) ClaimApplication(c *gin.Context) {
+	h.changeClaim(c, h.AppRepo.ClaimApplication, "Failed to claim application")
+}
+
+// POST /api/v1/agent/applications/:id/release
+// Агент возвращает взятую им заявку в общую очередь
+func (h *AgentHandler) ReleaseApplication(c *gin.Context) {
+	h.changeClaim(c, h.AppRepo.ReleaseApplication, "Failed to release application")
+}
+
+func (h *AgentHandler) changeClaim(c *gin.Context, change func(appID, agentID uint) (*models.ScoringApplication, error), op string) {
+	appID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidID)
+		return
+	}
+
+	agentID, _ := c.Get("userID")
+	app, err := change(uint(appID), agentID.(uint))
+	if err != nil {
+		apierror.Abort(c, applicationError(op, err))
+		return
+	}
+	c.JSON(http.StatusOK, toApplicationOut(app))
+}
+
+// applicationError - ошибки условных обновлений заявки из очереди
+func applicationError(op string, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrApplicationNotPending):
+		return apierror.ErrApplicationNotPending
+	case errors.Is(err, repository.ErrApplicationClaimed):
+		return apierror.ErrApplicationClaimed
+	case errors.Is(err, repository.ErrApplicationNotClaimed):
+		return apierror.ErrApplicationNotClaimed
+	default:
+		return apierror.Internal(op, err)
+	}
+}
+
+// Период статистики по умолчанию - последние 30 дней
+const defaultStatsPeriod = 30 * 24 * time.Hour
+
+// GET /api/v1/agent/stats?from=2025-01-01&to=2025-01-31
+func (h *AgentHandler) GetStats(c *gin.Context) {
+	var query schemas.StatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.Abort(c, apierror.ErrDateRange.Wrap(err))
+		return
+	}
+
+	// 'to' включительно: берем начало следующего дня как правую границу
+	to := time.Now()
+	if !query.To.IsZero() {
+		to = query.To.AddDate(0, 0, 1)
+	}
+	from := to.Add(-defaultStatsPeriod)
+	if !query.From.IsZero() {
+		from = query.From
+	}
+	if !from.Before(to) {
+		apierror.Abort(c, apierror.ErrDateRange)
+		return
+	}
+
+	stats, err := h.AppRepo.GetStats(c.Request.Context(), from, to)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to calculate stats", err))
+		return
+	}
+
+	out := schemas.StatsOut{
+		From:                    from,
+		To:                      to,
+		TotalApplications:       stats.TotalItems,
+		ByFinalDecision:         make(map[string]int64),
+		ByAgentStatus:           make(map[string]int64),
+		AvgColdScore:            stats.AvgColdScore,
+		AvgRequestedAmount:      stats.AvgRequestedAmount,
+		AvgRecommendedMaxAmount: stats.AvgRecommendedMaxAmount,
+		ReviewQueueAge: schemas.QueueAgeOut{
+			Size: stats.QueueSize,
+			P50:  stats.QueueAgeP50,
+			P90:  stats.QueueAgeP90,
+			P99:  stats.QueueAgeP99,
+		},
+		AgentThroughput: []schemas.AgentThroughputOut{},
+	}
+	for _, row := range stats.ByFinalDecision {
+		out.ByFinalDecision[row.Key] = row.Count
+	}
+	for _, row := range stats.ByAgentStatus {
+		out.ByAgentStatus[row.Key] = row.Count
+	}
+	if stats.TotalItems > 0 {
+		out.ApprovalRate = float64(stats.ApprovedItems) / float64(stats.TotalItems)
+	}
+	for _, t := range stats.AgentThroughputs {
+		out.AgentThroughput = append(out.AgentThroughput, schemas.AgentThroughputOut{
+			AgentID:  t.AgentID,
+			Email:    t.Email,
+			Decided:  t.Decided,
+			Approved: t.Approved,
+			Denied:   t.Denied,
+		})
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// toApplicationOut - конвертирует заявку в DTO для агента
+func toApplicationOut(app *models.ScoringApplication) schemas.ApplicationOut {
+	// Десериализуем InternalReasons из JSON-строки в []string
+	var reasons []string
+	if app.InternalReasons != "" {
+		// Игнорируем ошибку, если JSON невалидный, просто вернется пустой слайс
+		_ = json.Unmarshal([]byte(app.InternalReasons), &reasons)
+	}
+
+	out := schemas.ApplicationOut{
+		ID:                  app.ID,
+		CreatedAt:           app.CreatedAt,
+		User:                schemas.ApplicationUserOut{ID: app.User.ID, Email: app.User.Email},
+		RequestedAmount:     app.RequestedAmount,
+		Currency:            app.Currency,
+		RequestedAmountBase: app.RequestedAmountBase,
+		FinalDecision:       app.FinalDecision,
+		ColdScore:           app.ColdScore,
+		AIResponse:          app.AIResponse,
+		AgentStatus:         app.AgentStatus,
+		AgentNotes:          app.AgentNotes,
+		InternalReasons:     reasons, // <-- Передаем []string
+	}
+	// Истекшее закрепление уже ничего не блокирует - не показываем его
+	if app.ClaimedByID != nil && app.ClaimedAt != nil && time.Since(*app.ClaimedAt) < repository.ApplicationClaimTTL {
+		out.ClaimedByID = app.ClaimedByID
+		out.ClaimedAt = app.ClaimedAt
+	}
+	for _, request := range app.InfoRequests {
+		out.InfoRequests = append(out.InfoRequests, toInfoRequestOut(&request))
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/auth"
+	"ac-ai/internal/models"
+	"ac-ai/internal/repository"
+	"ac-ai/internal/schemas"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type APIKeyHandler struct {
+	Repo             *repository.APIKeyRepository
+	DefaultRateLimit int
+}
+
+func NewAPIKeyHandler(repo *repository.APIKeyRepository, defaultRateLimit int) *APIKeyHandler {
+	return &APIKeyHandler{
+		Repo:             repo,
+		DefaultRateLimit: defaultRateLimit,
+	}
+}
+
+// POST /api/v1/admin/api-keys
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	var req schemas.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+	if req.RateLimit == 0 {
+		req.RateLimit = h.DefaultRateLimit
+	}
+
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to generate API key", err))
+		return
+	}
+
+	adminID, _ := c.Get("userID")
+	apiKey, err := h.Repo.CreateAPIKey(req.Name, prefix, hash, req.Scopes, req.RateLimit, adminID.(uint))
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create API key", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, schemas.APIKeyCreatedOut{
+		APIKeyOut: toAPIKeyOut(apiKey),
+		Key:       key,
+	})
+}
+
+// GET /api/v1/admin/api-keys
+func (h *APIKeyHandler) List(c *gin.Context) {
+	keys, err := h.Repo.GetAPIKeys()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch API keys", err))
+		return
+	}
+
+	keysOut := []schemas.APIKeyOut{}
+	for _, key := range keys {
+		keysOut = append(keysOut, toAPIKeyOut(&key))
+	}
+	c.JSON(http.StatusOK, keysOut)
+}
+
+// DELETE /api/v1/admin/api-keys/:id
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidID)
+		return
+	}
+
+	key, err := h.Repo.RevokeAPIKey(uint(id))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Abort(c, apierror.ErrAPIKeyNotFound)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to revoke API key", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIKeyOut(key))
+}
+
+func toAPIKeyOut(key *models.APIKey) schemas.APIKeyOut {
+	return schemas.APIKeyOut{
+		ID:         key.ID,
+		CreatedAt:  key.CreatedAt,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		RateLimit:  key.RateLimit,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
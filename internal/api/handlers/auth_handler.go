@@ -1,23 +1,57 @@
 package handlers
 
 import (
+	"ac-ai/internal/api/apierror"
 	"ac-ai/internal/auth"
+	"ac-ai/internal/lockout"
 	"ac-ai/internal/models"
 	"ac-ai/internal/repository"
 	"ac-ai/internal/schemas"
+	"ac-ai/internal/services"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type AuthHandler struct {
-	UserRepo *repository.UserRepository
-	JWT      *auth.JWTService
+	UserRepo   *repository.UserRepository
+	TokenRepo  *repository.TokenRepository
+	JWT        *auth.JWTService
+	RefreshTTL time.Duration
+	// Сотрудники (AGENT и выше) не могут войти без подключенного TOTP
+	MFARequiredForStaff bool
+	Lockout             *lockout.Guard
+	AuditRepo           *repository.AuditRepository
+	Emails              *services.AccountEmailService
 }
 
-func NewAuthHandler(repo *repository.UserRepository, jwt *auth.JWTService) *AuthHandler {
-	return &AuthHandler{UserRepo: repo, JWT: jwt}
+func NewAuthHandler(
+	repo *repository.UserRepository,
+	tokenRepo *repository.TokenRepository,
+	jwt *auth.JWTService,
+	refreshTTL time.Duration,
+	mfaRequiredForStaff bool,
+	guard *lockout.Guard,
+	auditRepo *repository.AuditRepository,
+	emails *services.AccountEmailService,
+) *AuthHandler {
+	return &AuthHandler{
+		UserRepo:            repo,
+		TokenRepo:           tokenRepo,
+		JWT:                 jwt,
+		RefreshTTL:          refreshTTL,
+		MFARequiredForStaff: mfaRequiredForStaff,
+		Lockout:             guard,
+		AuditRepo:           auditRepo,
+		Emails:              emails,
+	}
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -25,45 +59,63 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	// Валидация JSON
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.FromBind(err))
 		return
 	}
 
-	// Проверка на CLIENT и ProfileData
-	if req.Role == models.RoleClient && req.ProfileData == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "profile_data is required for CLIENT role"})
+	// Публичная регистрация - только для клиентов, профиль обязателен
+	if req.ProfileData == nil {
+		apierror.Abort(c, apierror.ErrProfileRequired)
 		return
 	}
 
 	// Проверка, что юзер не существует
 	if _, err := h.UserRepo.GetUserByEmail(req.Email); err == nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Email already registered"})
+		apierror.Abort(c, apierror.ErrEmailTaken)
 		return
 	}
 
 	// Хешируем пароль
 	hashedPassword, err := auth.HashPassword(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		apierror.Abort(c, apierror.Internal("Failed to hash password", err))
 		return
 	}
 
 	// Создаем юзера (и профиль)
 	user, err := h.UserRepo.CreateUser(&req, hashedPassword)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user", "details": err.Error()})
+		apierror.Abort(c, apierror.Internal("Failed to create user", err))
 		return
 	}
 
-	// (Можно вернуть DTO вместо модели, но для демо сойдет)
-	c.JSON(http.StatusCreated, user)
+	// Письмо с подтверждением email - в фоне, регистрация от почты не зависит
+	go func(ctx context.Context, user models.User) {
+		if err := h.Emails.SendVerification(ctx, &user); err != nil {
+			slog.ErrorContext(ctx, "Failed to send verification email", slog.Uint64("target_user_id", uint64(user.ID)), slog.Any("error", err))
+		}
+	}(context.WithoutCancel(c.Request.Context()), *user)
+
+	c.JSON(http.StatusCreated, schemas.UserOut{
+		ID:              user.ID,
+		CreatedAt:       user.CreatedAt,
+		Email:           user.Email,
+		Role:            user.Role,
+		Status:          user.Status,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+	})
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req schemas.LoginRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	// Заблокированный аккаунт или IP отсекаем до bcrypt
+	if !h.checkLockout(c, req.Email) {
 		return
 	}
 
@@ -71,28 +123,244 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	user, err := h.UserRepo.GetUserByEmail(req.Email)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+			// Несуществующий email тоже считаем неудачей - иначе по блокировкам видно, какие email есть
+			h.registerLoginFailure(c, req.Email)
+			apierror.Abort(c, apierror.ErrInvalidCredentials)
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		apierror.Abort(c, apierror.Internal("Failed to look up user", err))
 		return
 	}
 
 	// Проверяем пароль
 	if !auth.CheckPasswordHash(req.Password, user.PasswordHash) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		h.registerLoginFailure(c, req.Email)
+		apierror.Abort(c, apierror.ErrInvalidCredentials)
+		return
+	}
+
+	// Приглашенные и деактивированные сотрудники войти не могут
+	if user.Status != models.UserStatusActive {
+		apierror.Abort(c, apierror.ErrAccountInactive)
+		return
+	}
+
+	// Настройки хэширования поменялись - пересчитываем, пока пароль есть в открытом виде.
+	// Только для активных аккаунтов: хэш неактивного не трогаем.
+	// Новый хэш гасит ранее отправленные ссылки сброса пароля (они привязаны к хэшу) -
+	// это допустимо: пользователь только что доказал, что пароль знает
+	if auth.PasswordNeedsRehash(user.PasswordHash) {
+		if newHash, err := auth.HashPassword(req.Password); err == nil {
+			if err := h.UserRepo.RehashPassword(user.ID, user.PasswordHash, newHash); err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to rehash password", slog.Uint64("target_user_id", uint64(user.ID)), slog.Any("error", err))
+			}
+		}
+	}
+
+	// Второй фактор: вместо токенов - короткий challenge-токен
+	if purpose := h.mfaPurpose(user); purpose != "" {
+		h.respondWithChallenge(c, user, purpose)
+		return
+	}
+
+	h.startSession(c, user)
+}
+
+// mfaPurpose - какой шаг второго фактора нужен для входа ("" - не нужен)
+func (h *AuthHandler) mfaPurpose(user *models.User) string {
+	if user.MFAEnabled() {
+		return auth.PurposeMFAVerify
+	}
+	if h.MFARequiredForStaff && user.IsStaff() {
+		return auth.PurposeMFAEnroll
+	}
+	return ""
+}
+
+// POST /api/v1/auth/accept-invite
+// Сотрудник задает пароль по приглашению администратора
+func (h *AuthHandler) AcceptInvite(c *gin.Context) {
+	var req schemas.AcceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
 		return
 	}
 
-	// Создаем токен
-	token, err := h.JWT.CreateToken(user.ID, user.Role)
+	hashedPassword, err := auth.HashPassword(req.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		apierror.Abort(c, apierror.Internal("Failed to hash password", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, schemas.LoginResponse{
-		AccessToken: token,
-		TokenType:   "Bearer",
-	})
-}
\ No newline at end of file
+	user, err := h.UserRepo.AcceptStaffInvite(auth.HashOpaqueToken(req.Token), hashedPassword)
+	if err != nil {
+		if errors.Is(err, repository.ErrInviteInvalid) {
+			apierror.Abort(c, apierror.ErrInviteInvalid)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to accept invite", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toStaffUserOut(user))
+}
+
+// POST /api/v1/auth/refresh
+// Refresh-токен одноразовый: в ответ выдается новая пара токенов
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req schemas.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	newToken, newHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create token", err))
+		return
+	}
+
+	old, err := h.TokenRepo.RotateRefreshToken(auth.HashOpaqueToken(req.RefreshToken), newHash, time.Now().Add(h.RefreshTTL))
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrRefreshTokenReused):
+			slog.WarnContext(c.Request.Context(), "Refresh token reuse detected, token family revoked", slog.String("category", "security"), slog.Uint64("target_user_id", uint64(old.UserID)), slog.String("family_id", old.FamilyID))
+			apierror.Abort(c, apierror.ErrRefreshReused)
+		case errors.Is(err, repository.ErrRefreshTokenInvalid):
+			apierror.Abort(c, apierror.ErrRefreshInvalid)
+		default:
+			apierror.Abort(c, apierror.Internal("Failed to refresh token", err))
+		}
+		return
+	}
+
+	// Роль берем из БД, а не из старого токена - она могла измениться
+	user, err := h.UserRepo.GetUserByID(old.UserID)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrTokenInvalid)
+		return
+	}
+	if user.Status != models.UserStatusActive {
+		apierror.Abort(c, apierror.ErrAccountInactive)
+		return
+	}
+
+	access, err := h.JWT.CreateToken(user.ID, user.Role)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create token", err))
+		return
+	}
+	if err := h.TokenRepo.AttachAccessToken(newHash, access.ID, access.ExpiresAt); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create token", err))
+		return
+	}
+	c.JSON(http.StatusOK, h.tokenResponse(access, newToken))
+}
+
+// POST /api/v1/auth/logout
+// Отзывает текущий access-токен и (если передан) всю семью refresh-токенов
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req schemas.LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	userID, _ := c.Get("userID")
+	// jti и exp есть у каждого токена, прошедшего AuthMiddleware
+	if err := h.TokenRepo.RevokeAccessToken(c.GetString("tokenID"), c.GetTime("tokenExpiresAt")); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to revoke token", err))
+		return
+	}
+
+	if req.RefreshToken != "" {
+		err := h.TokenRepo.RevokeRefreshFamily(auth.HashOpaqueToken(req.RefreshToken), userID.(uint))
+		if err != nil && !errors.Is(err, repository.ErrRefreshTokenInvalid) {
+			apierror.Abort(c, apierror.Internal("Failed to revoke token", err))
+			return
+		}
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// startSession - access-токен и новая семья refresh-токенов (успешный вход)
+func (h *AuthHandler) startSession(c *gin.Context, user *models.User) {
+	tokens, err := h.newSession(user)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create token", err))
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *AuthHandler) newSession(user *models.User) (*schemas.LoginResponse, error) {
+	// Счетчик неудач сбрасываем только после полного входа (с учетом MFA)
+	if err := h.Lockout.RegisterSuccess(user.Email); err != nil {
+		slog.Error("Failed to reset login failures", slog.Uint64("target_user_id", uint64(user.ID)), slog.Any("error", err))
+	}
+
+	familyID, err := auth.NewRandomID()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, refreshHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	access, err := h.JWT.CreateToken(user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.TokenRepo.CreateRefreshToken(user.ID, familyID, refreshHash, time.Now().Add(h.RefreshTTL), access.ID, access.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return h.tokenResponse(access, refreshToken), nil
+}
+
+func (h *AuthHandler) tokenResponse(access *auth.AccessToken, refreshToken string) *schemas.LoginResponse {
+	return &schemas.LoginResponse{
+		AccessToken:  access.Token,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(h.JWT.ExpiresIn().Seconds()),
+		RefreshToken: refreshToken,
+	}
+}
+
+// checkLockout - false, если аккаунт или IP временно заблокирован (ответ уже отправлен)
+func (h *AuthHandler) checkLockout(c *gin.Context, email string) bool {
+	wait, err := h.Lockout.Check(email, c.ClientIP())
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to check login attempts", err))
+		return false
+	}
+	if wait > 0 {
+		retryAfter := int(wait.Round(time.Second).Seconds())
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		c.Header("Retry-After", strconv.Itoa(retryAfter))
+		apierror.Abort(c, apierror.ErrLoginLocked.With("retry_after", retryAfter))
+		return false
+	}
+	return true
+}
+
+// registerLoginFailure - учитывает неудачу и пишет в аудит начавшиеся блокировки
+func (h *AuthHandler) registerLoginFailure(c *gin.Context, email string) {
+	ip := c.ClientIP()
+	lockouts, err := h.Lockout.RegisterFailure(email, ip)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to register login failure", slog.Any("error", err))
+	}
+
+	for _, l := range lockouts {
+		slog.WarnContext(c.Request.Context(), "Login locked", slog.String("category", "security"), slog.String("key", l.Key), slog.Int("failures", l.Failures), slog.Time("until", l.Until))
+		h.AuditRepo.Record(&models.AuditEvent{
+			Action:  models.AuditLoginLocked,
+			Subject: l.Key,
+			IP:      ip,
+			Details: fmt.Sprintf("%d failed attempts, locked until %s", l.Failures, l.Until.Format(time.RFC3339)),
+		})
+	}
+}
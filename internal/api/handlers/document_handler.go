@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/models"
+	"ac-ai/internal/repository"
+	"ac-ai/internal/schemas"
+	"ac-ai/internal/services"
+	"ac-ai/internal/storage"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Запас на остальные поля multipart-формы сверх размера файла
+const multipartOverhead = 1 << 20
+
+type DocumentHandler struct {
+	DocRepo    *repository.DocumentRepository
+	DocService *services.DocumentService
+}
+
+func NewDocumentHandler(repo *repository.DocumentRepository, service *services.DocumentService) *DocumentHandler {
+	return &DocumentHandler{
+		DocRepo:    repo,
+		DocService: service,
+	}
+}
+
+// POST /api/v1/documents (multipart: kind, file)
+func (h *DocumentHandler) Upload(c *gin.Context) {
+	// 1. Ограничиваем тело запроса до парсинга формы
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.DocService.MaxSize()+multipartOverhead)
+
+	var form schemas.DocumentUploadForm
+	if err := c.ShouldBind(&form); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			apierror.Abort(c, apierror.ErrFileTooLarge)
+			return
+		}
+		apierror.Abort(c, apierror.ErrFileRequired.Wrap(err))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		apierror.Abort(c, apierror.ErrFileUnreadable.Wrap(err))
+		return
+	}
+	defer file.Close()
+
+	userID, _ := c.Get("userID")
+
+	// 2. Проверка, антивирус и сохранение
+	doc, err := h.DocService.Upload(c.Request.Context(), userID.(uint), form.Kind, fileHeader.Filename, file)
+	if err != nil {
+		respondUploadError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toDocumentOut(doc))
+}
+
+// respondUploadError - ответ на ошибку DocumentService.Upload (общий для документов и вложений)
+func respondUploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDocumentTooLarge):
+		apierror.Abort(c, apierror.ErrFileTooLarge)
+	case errors.Is(err, services.ErrUnsupportedDocumentType):
+		apierror.Abort(c, apierror.ErrFileType)
+	case errors.Is(err, storage.ErrInfected):
+		apierror.Abort(c, apierror.ErrFileRejected)
+	default:
+		apierror.Abort(c, apierror.Internal("Failed to upload document", err))
+	}
+}
+
+// GET /api/v1/documents
+func (h *DocumentHandler) ListMine(c *gin.Context) {
+	userID, _ := c.Get("userID")
+	h.listByUser(c, userID.(uint))
+}
+
+// GET /api/v1/agent/clients/:id/documents
+func (h *DocumentHandler) ListByClient(c *gin.Context) {
+	clientID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidID)
+		return
+	}
+	h.listByUser(c, uint(clientID))
+}
+
+func (h *DocumentHandler) listByUser(c *gin.Context, userID uint) {
+	docs, err := h.DocRepo.GetDocumentsByUser(userID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch documents", err))
+		return
+	}
+
+	docsOut := []schemas.DocumentOut{}
+	for _, doc := range docs {
+		docsOut = append(docsOut, toDocumentOut(&doc))
+	}
+	c.JSON(http.StatusOK, docsOut)
+}
+
+// GET /api/v1/agent/documents/:id/file
+func (h *DocumentHandler) Download(c *gin.Context) {
+	doc, ok := h.findDocument(c)
+	if !ok {
+		return
+	}
+
+	content, err := h.DocService.Open(c.Request.Context(), doc)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			apierror.Abort(c, apierror.ErrDocumentFileNotFound)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to open document", err))
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", doc.ContentType)
+	c.Header("Content-Disposition", contentDisposition("inline", doc.Filename))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, content); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to stream document", slog.Uint64("document_id", uint64(doc.ID)), slog.Any("error", err))
+	}
+}
+
+// contentDisposition - заголовок по RFC 6266: filename - ASCII-замена для старых
+// клиентов, filename* - исходное имя в UTF-8 (RFC 8187)
+func contentDisposition(disposition, filename string) string {
+	var fallback, encoded strings.Builder
+	for _, r := range filename {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			fallback.WriteByte('_')
+		} else {
+			fallback.WriteRune(r)
+		}
+	}
+	for i := 0; i < len(filename); i++ {
+		if b := filename[i]; isAttrChar(b) {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, disposition, fallback.String(), encoded.String())
+}
+
+// isAttrChar - символы, которые в filename* передаются без процентного кодирования
+func isAttrChar(b byte) bool {
+	switch {
+	case 'a' <= b && b <= 'z', 'A' <= b && b <= 'Z', '0' <= b && b <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", b) >= 0
+}
+
+// POST /api/v1/agent/documents/:id/review
+func (h *DocumentHandler) Review(c *gin.Context) {
+	docID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidID)
+		return
+	}
+
+	var req schemas.DocumentReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	agentID, _ := c.Get("userID")
+
+	doc, err := h.DocRepo.ReviewDocument(uint(docID), agentID.(uint), req.Status, req.Notes)
+	if err != nil {
+		if errors.Is(err, repository.ErrDocumentNotPending) {
+			apierror.Abort(c, apierror.ErrDocumentNotPending)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to review document", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, toDocumentOut(doc))
+}
+
+func (h *DocumentHandler) findDocument(c *gin.Context) (*models.Document, bool) {
+	docID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidID)
+		return nil, false
+	}
+
+	doc, err := h.DocRepo.GetDocumentByID(uint(docID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			apierror.Abort(c, apierror.ErrDocumentNotFound)
+			return nil, false
+		}
+		apierror.Abort(c, apierror.Internal("Failed to fetch document", err))
+		return nil, false
+	}
+	return doc, true
+}
+
+func toDocumentOut(doc *models.Document) schemas.DocumentOut {
+	return schemas.DocumentOut{
+		ID:          doc.ID,
+		CreatedAt:   doc.CreatedAt,
+		UserID:      doc.UserID,
+		Kind:        doc.Kind,
+		Filename:    doc.Filename,
+		ContentType: doc.ContentType,
+		Size:        doc.Size,
+		Status:      doc.Status,
+		ReviewNotes: doc.ReviewNotes,
+		ReviewedAt:  doc.ReviewedAt,
+	}
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"ac-ai/internal/events"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Интервал пустых сообщений, чтобы прокси не закрывали "тихое" соединение
+const sseHeartbeatInterval = 25 * time.Second
+
+type EventsHandler struct {
+	Bus events.Bus
+	// Закрывается при остановке сервера: потоки завершаем сами, иначе
+	// http.Server.Shutdown ждал бы их до конца таймаута
+	Done <-chan struct{}
+}
+
+func NewEventsHandler(ctx context.Context, bus events.Bus) *EventsHandler {
+	return &EventsHandler{Bus: bus, Done: ctx.Done()}
+}
+
+// GET /api/v1/agent/events
+// Поток Server-Sent Events: application.created, application.claimed, application.released,
+// application.decided, application.info_requested, application.info_provided
+func (h *EventsHandler) Stream(c *gin.Context) {
+	ch, unsubscribe := h.Bus.Subscribe()
+	defer unsubscribe()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Отключаем буферизацию в nginx
+
+	// Поток живет дольше SERVER_WRITE_TIMEOUT_SECONDS - снимаем дедлайн записи
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-h.Done:
+			// Клиент переподключится к другому инстансу
+			return false
+		case event, ok := <-ch:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", gin.H{"at": time.Now()})
+			return true
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/export"
+	"ac-ai/internal/models"
+	"ac-ai/internal/repository"
+	"ac-ai/internal/schemas"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	UserRepo *repository.UserRepository
+	AppRepo  *repository.ApplicationRepository
+}
+
+func NewExportHandler(userRepo *repository.UserRepository, appRepo *repository.ApplicationRepository) *ExportHandler {
+	return &ExportHandler{
+		UserRepo: userRepo,
+		AppRepo:  appRepo,
+	}
+}
+
+// GET /api/v1/agent/export/applications?format=csv|xlsx&view=all|review
+func (h *ExportHandler) ExportApplications(c *gin.Context) {
+	var query schemas.ApplicationExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	writer, ok := startExport(c, "applications", query.Format)
+	if !ok {
+		return
+	}
+
+	header := []any{
+		"id", "created_at", "user_id", "user_email", "requested_amount", "currency",
+		"final_decision", "cold_score", "agent_status", "agent_notes", "internal_reasons",
+	}
+
+	err := writeRows(c, writer, header, func(emit func([]any) error) error {
+		return h.AppRepo.StreamApplications(query.View, func(batch []models.ScoringApplication) error {
+			for _, app := range batch {
+				var reasons []string
+				if app.InternalReasons != "" {
+					_ = json.Unmarshal([]byte(app.InternalReasons), &reasons)
+				}
+				if err := emit([]any{
+					app.ID, app.CreatedAt, app.UserID, app.User.Email, app.RequestedAmount, app.Currency,
+					app.FinalDecision, app.ColdScore, app.AgentStatus, app.AgentNotes, strings.Join(reasons, "; "),
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Export of applications failed", slog.Any("error", err))
+	}
+}
+
+// GET /api/v1/agent/export/clients?format=csv|xlsx
+func (h *ExportHandler) ExportClients(c *gin.Context) {
+	var query schemas.ExportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	writer, ok := startExport(c, "clients", query.Format)
+	if !ok {
+		return
+	}
+
+	header := []any{
+		"id", "email", "created_at", "income", "monthly_payments", "currency",
+		"credit_history", "job_experience_years", "age", "income_proof",
+	}
+
+	err := writeRows(c, writer, header, func(emit func([]any) error) error {
+		return h.UserRepo.StreamUsersByRole(models.RoleClient, func(batch []models.User) error {
+			for _, user := range batch {
+				// Как и в GetAllClients: клиенты без профиля не выгружаются
+				if user.FinancialProfile.ID == 0 {
+					continue
+				}
+				p := user.FinancialProfile
+				if err := emit([]any{
+					user.ID, user.Email, user.CreatedAt, p.Income, p.MonthlyPayments, p.Currency,
+					p.CreditHistory, p.JobExperienceYears, p.Age, p.IncomeProof,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Export of clients failed", slog.Any("error", err))
+	}
+}
+
+// startExport - создает writer поверх ответа и выставляет заголовки скачивания.
+// Writer создается первым: при неизвестном формате ответ еще не начат
+// и ошибка уходит обычным JSON, а не пустым вложением со статусом 200
+func startExport(c *gin.Context, name, format string) (export.RowWriter, bool) {
+	writer, err := export.NewRowWriter(format, c.Writer)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrExportFormat.Wrap(err))
+		return nil, false
+	}
+
+	// Большая выгрузка пишется дольше SERVER_WRITE_TIMEOUT_SECONDS - снимаем дедлайн записи
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102"), format)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+	return writer, true
+}
+
+// writeRows - пишет заголовок и строки прямо в ответ по мере чтения из БД.
+// После первой записи статус уже отправлен, поэтому ошибки только логируются
+func writeRows(c *gin.Context, writer export.RowWriter, header []any, stream func(emit func([]any) error) error) error {
+	if err := writer.WriteRow(header); err != nil {
+		return err
+	}
+
+	err := stream(func(row []any) error {
+		return writer.WriteRow(row)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
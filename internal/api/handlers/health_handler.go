@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"ac-ai/internal/database"
+	"ac-ai/internal/services"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	readyzCheckTimeout = 2 * time.Second
+	// Результат проверки OpenAI кэшируем: пробы идут каждые несколько секунд с каждого пода
+	aiCheckCacheTTL = 30 * time.Second
+	// Схема меняется только при выкатке, читать schema_migrations на каждую пробу незачем
+	migrationsCheckCacheTTL = 30 * time.Second
+)
+
+type HealthHandler struct {
+	DB       *gorm.DB
+	Migrator *database.Migrator
+	AI       *services.AIService // nil - провайдер ИИ не проверяем
+
+	mu                  sync.Mutex
+	aiCheckedAt         time.Time
+	aiErr               error
+	migrationsCheckedAt time.Time
+	migrationsErr       error
+}
+
+func NewHealthHandler(db *gorm.DB, migrator *database.Migrator, ai *services.AIService) *HealthHandler {
+	return &HealthHandler{DB: db, Migrator: migrator, AI: ai}
+}
+
+// GET /healthz
+// Liveness: процесс жив и обслуживает HTTP. Зависимости не проверяем,
+// иначе оркестратор будет перезапускать поды при падении БД
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// GET /readyz
+// Readiness: можно ли направлять трафик (БД доступна, схема актуальна, ИИ отвечает)
+func (h *HealthHandler) Ready(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyzCheckTimeout)
+	defer cancel()
+
+	checks := gin.H{}
+	ready := true
+	fail := func(name, reason string, err error) {
+		slog.WarnContext(ctx, "Readiness check failed", slog.String("check", name), slog.Any("error", err))
+		checks[name] = reason
+		ready = false
+	}
+
+	if err := h.pingDB(ctx); err != nil {
+		fail("database", "unreachable", err)
+	} else {
+		checks["database"] = "ok"
+		if err := h.checkMigrations(ctx); err != nil {
+			fail("migrations", "pending", err)
+		} else {
+			checks["migrations"] = "ok"
+		}
+	}
+
+	if h.AI != nil {
+		if err := h.checkAI(ctx); err != nil {
+			fail("ai", "unreachable", err)
+		} else {
+			checks["ai"] = "ok"
+		}
+	}
+
+	status, code := "ok", http.StatusOK
+	if !ready {
+		status, code = "unavailable", http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{"status": status, "checks": checks})
+}
+
+func (h *HealthHandler) pingDB(ctx context.Context) error {
+	sqlDB, err := h.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (h *HealthHandler) checkAI(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if time.Since(h.aiCheckedAt) < aiCheckCacheTTL {
+		return h.aiErr
+	}
+	h.aiErr = h.AI.Ping(ctx)
+	h.aiCheckedAt = time.Now()
+	return h.aiErr
+}
+
+// checkMigrations - как checkAI, но ошибку БД (например, таймаут пробы) не кэшируем:
+// иначе под оставался бы неготовым еще 30 секунд после восстановления БД
+func (h *HealthHandler) checkMigrations(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if time.Since(h.migrationsCheckedAt) < migrationsCheckCacheTTL {
+		return h.migrationsErr
+	}
+	err := h.Migrator.CheckCurrent(ctx)
+	if err != nil && !errors.Is(err, database.ErrMigrationsPending) {
+		return err
+	}
+	h.migrationsErr = err
+	h.migrationsCheckedAt = time.Now()
+	return err
+}
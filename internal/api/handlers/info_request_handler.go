@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/models"
+	"ac-ai/internal/repository"
+	"ac-ai/internal/schemas"
+	"ac-ai/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Максимум вложений в одном ответе клиента
+const maxInfoResponseAttachments = 5
+
+type InfoRequestHandler struct {
+	AppRepo    *repository.ApplicationRepository
+	DocService *services.DocumentService
+	// Сколько клиент может отвечать, прежде чем заявка будет отклонена
+	ExpireAfter time.Duration
+}
+
+func NewInfoRequestHandler(appRepo *repository.ApplicationRepository, docService *services.DocumentService, expireAfter time.Duration) *InfoRequestHandler {
+	return &InfoRequestHandler{
+		AppRepo:     appRepo,
+		DocService:  docService,
+		ExpireAfter: expireAfter,
+	}
+}
+
+// POST /api/v1/agent/applications/:id/request-info
+func (h *InfoRequestHandler) RequestInfo(c *gin.Context) {
+	appID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidID)
+		return
+	}
+
+	var req schemas.InfoRequestCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	agentID, _ := c.Get("userID")
+
+	request, err := h.AppRepo.RequestInfo(uint(appID), agentID.(uint), req.Kind, req.Message, time.Now().Add(h.ExpireAfter))
+	if err != nil {
+		apierror.Abort(c, applicationError("Failed to request information", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, toInfoRequestOut(request))
+}
+
+// GET /api/v1/info-requests
+func (h *InfoRequestHandler) ListMine(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	requests, err := h.AppRepo.GetOpenInfoRequestsForUser(userID.(uint))
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to fetch info requests", err))
+		return
+	}
+
+	requestsOut := []schemas.InfoRequestOut{}
+	for _, request := range requests {
+		requestsOut = append(requestsOut, toInfoRequestOut(&request))
+	}
+	c.JSON(http.StatusOK, requestsOut)
+}
+
+// POST /api/v1/info-requests/:id/respond (multipart: text, attachments)
+func (h *InfoRequestHandler) Respond(c *gin.Context) {
+	requestID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrInvalidID)
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInfoResponseAttachments*h.DocService.MaxSize()+multipartOverhead)
+
+	var form schemas.InfoResponseForm
+	if err := c.ShouldBind(&form); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	// 1. Проверяем запрос до загрузки файлов, чтобы не сохранять их зря
+	if _, err := h.AppRepo.GetOpenInfoRequest(uint(requestID), userID.(uint)); err != nil {
+		if errors.Is(err, repository.ErrInfoRequestNotOpen) {
+			apierror.Abort(c, apierror.ErrInfoRequestNotFound)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to fetch info request", err))
+		return
+	}
+
+	// 2. Вложения проходят ту же проверку, что и обычные документы.
+	// Если ответ не сохранится, загруженные файлы удаляем, чтобы не оставлять сирот
+	var uploaded []*models.Document
+	saved := false
+	defer func() {
+		if !saved {
+			h.discardAttachments(c, uploaded)
+		}
+	}()
+
+	if multipartForm, err := c.MultipartForm(); err == nil {
+		files := multipartForm.File["attachments"]
+		if len(files) > maxInfoResponseAttachments {
+			apierror.Abort(c, apierror.ErrTooManyAttachments)
+			return
+		}
+		for _, fileHeader := range files {
+			file, err := fileHeader.Open()
+			if err != nil {
+				apierror.Abort(c, apierror.ErrFileUnreadable.Wrap(err))
+				return
+			}
+			doc, err := h.DocService.Upload(c.Request.Context(), userID.(uint), models.DocumentKindOther, fileHeader.Filename, file)
+			file.Close()
+			if err != nil {
+				respondUploadError(c, err)
+				return
+			}
+			uploaded = append(uploaded, doc)
+		}
+	}
+
+	// 3. Сохраняем ответ - заявка возвращается в очередь агента
+	attachmentIDs := make([]uint, 0, len(uploaded))
+	for _, doc := range uploaded {
+		attachmentIDs = append(attachmentIDs, doc.ID)
+	}
+	request, err := h.AppRepo.RespondToInfoRequest(uint(requestID), userID.(uint), form.Text, attachmentIDs)
+	if err != nil {
+		if errors.Is(err, repository.ErrInfoRequestNotOpen) {
+			apierror.Abort(c, apierror.ErrInfoRequestClosed)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to save response", err))
+		return
+	}
+	saved = true
+
+	c.JSON(http.StatusOK, toInfoRequestOut(request))
+}
+
+// discardAttachments - удаляет вложения несохраненного ответа. Ошибки только
+// логируются: клиенту уже уходит ошибка основного запроса
+func (h *InfoRequestHandler) discardAttachments(c *gin.Context, docs []*models.Document) {
+	for _, doc := range docs {
+		if err := h.DocService.Discard(c.Request.Context(), doc); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to discard attachment", slog.Uint64("document_id", uint64(doc.ID)), slog.Any("error", err))
+		}
+	}
+}
+
+func toInfoRequestOut(request *models.InfoRequest) schemas.InfoRequestOut {
+	out := schemas.InfoRequestOut{
+		ID:            request.ID,
+		ApplicationID: request.ApplicationID,
+		CreatedAt:     request.CreatedAt,
+		Kind:          request.Kind,
+		Message:       request.Message,
+		Status:        request.Status,
+		ExpiresAt:     request.ExpiresAt,
+		ResponseText:  request.ResponseText,
+		RespondedAt:   request.RespondedAt,
+	}
+	for _, doc := range request.Attachments {
+		out.Attachments = append(out.Attachments, toDocumentOut(&doc))
+	}
+	return out
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/auth"
+	"ac-ai/internal/models"
+	"ac-ai/internal/repository"
+	"ac-ai/internal/schemas"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Название сервиса в приложении-аутентификаторе
+const totpIssuer = "AC-AI"
+
+// Сколько кодов восстановления выдается при подключении TOTP
+const recoveryCodesCount = 10
+
+// respondWithChallenge - первый шаг входа с MFA
+func (h *AuthHandler) respondWithChallenge(c *gin.Context, user *models.User, purpose string) {
+	token, err := h.JWT.CreateChallengeToken(user.ID, user.Role, purpose)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to create token", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.MFAChallengeResponse{
+		MFARequired:           true,
+		MFAEnrollmentRequired: purpose == auth.PurposeMFAEnroll,
+		MFAToken:              token,
+		ExpiresIn:             int(auth.ChallengeTokenTTL.Seconds()),
+	})
+}
+
+// POST /api/v1/auth/mfa/verify
+// Второй шаг входа: TOTP-код или одноразовый код восстановления
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req schemas.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	claims, err := h.JWT.ValidateChallengeToken(req.MFAToken, auth.PurposeMFAVerify)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrMFATokenInvalid)
+		return
+	}
+
+	user, err := h.UserRepo.GetUserByID(claims.UserID)
+	if err != nil || user.Status != models.UserStatusActive || !user.MFAEnabled() {
+		apierror.Abort(c, apierror.ErrMFATokenInvalid)
+		return
+	}
+
+	// Перебор 6-значных кодов ограничен тем же счетчиком, что и перебор паролей
+	if !h.checkLockout(c, user.Email) {
+		return
+	}
+
+	var ok bool
+	if req.Code != "" {
+		ok, err = h.acceptTOTPCode(user, req.Code)
+	} else {
+		ok, err = h.UserRepo.UseRecoveryCode(user.ID, auth.HashOpaqueToken(auth.NormalizeRecoveryCode(req.RecoveryCode)))
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to verify code", err))
+		return
+	}
+	if !ok {
+		h.registerLoginFailure(c, user.Email)
+		apierror.Abort(c, apierror.ErrInvalidMFACode)
+		return
+	}
+
+	h.startSession(c, user)
+}
+
+// POST /api/v1/auth/mfa/totp/enroll
+// Генерирует секрет. MFA включится только после подтверждения кодом
+func (h *AuthHandler) EnrollTOTP(c *gin.Context) {
+	userID, _ := c.Get("userID")
+
+	user, err := h.UserRepo.GetUserByID(userID.(uint))
+	if err != nil {
+		apierror.Abort(c, apierror.ErrUserNotFound)
+		return
+	}
+	if user.MFAEnabled() {
+		apierror.Abort(c, apierror.ErrTOTPEnabled)
+		return
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to generate secret", err))
+		return
+	}
+	if err := h.UserRepo.SetPendingTOTPSecret(user.ID, secret); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to save secret", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, schemas.TOTPEnrollResponse{
+		Secret:     secret,
+		OTPAuthURI: auth.TOTPURI(totpIssuer, user.Email, secret),
+	})
+}
+
+// POST /api/v1/auth/mfa/totp/confirm
+// Первый верный код включает MFA и выдает коды восстановления
+func (h *AuthHandler) ConfirmTOTP(c *gin.Context) {
+	var req schemas.TOTPConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	userID, _ := c.Get("userID")
+
+	user, err := h.UserRepo.GetUserByID(userID.(uint))
+	if err != nil {
+		apierror.Abort(c, apierror.ErrUserNotFound)
+		return
+	}
+	if user.MFAEnabled() {
+		apierror.Abort(c, apierror.ErrTOTPEnabled)
+		return
+	}
+	if user.TOTPSecret == "" {
+		apierror.Abort(c, apierror.ErrTOTPNotStarted)
+		return
+	}
+
+	step, ok := auth.ValidateTOTP(user.TOTPSecret, req.Code, time.Now())
+	if !ok {
+		apierror.Abort(c, apierror.ErrInvalidMFACode)
+		return
+	}
+
+	codes, err := auth.NewRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to generate recovery codes", err))
+		return
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashOpaqueToken(auth.NormalizeRecoveryCode(code))
+	}
+	if err := h.UserRepo.EnableTOTP(user.ID, step, hashes); err != nil {
+		if errors.Is(err, repository.ErrTOTPAlreadyEnabled) {
+			apierror.Abort(c, apierror.ErrTOTPEnabled)
+			return
+		}
+		apierror.Abort(c, apierror.Internal("Failed to enable TOTP", err))
+		return
+	}
+
+	resp := schemas.TOTPConfirmResponse{RecoveryCodes: codes}
+
+	// Подключение было частью входа - сразу завершаем вход
+	if c.GetBool("mfaEnrollment") {
+		if user.Status != models.UserStatusActive {
+			apierror.Abort(c, apierror.ErrAccountInactive)
+			return
+		}
+		resp.Tokens, err = h.newSession(user)
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to create token", err))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// acceptTOTPCode - проверка кода с защитой от повторного использования
+func (h *AuthHandler) acceptTOTPCode(user *models.User, code string) (bool, error) {
+	step, ok := auth.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return h.UserRepo.AdvanceTOTPStep(user.ID, step)
+}
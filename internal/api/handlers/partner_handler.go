@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/metrics"
+	"ac-ai/internal/models"
+	"ac-ai/internal/money"
+	"ac-ai/internal/schemas"
+	"ac-ai/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerHandler struct {
+	FX services.FXRateProvider
+}
+
+func NewPartnerHandler(fx services.FXRateProvider) *PartnerHandler {
+	return &PartnerHandler{FX: fx}
+}
+
+// POST /api/v1/partner/scoring
+// Предварительный ("холодный") скоринг для маркетплейсов. Без AI-анализа и без сохранения:
+// профиль не принадлежит зарегистрированному клиенту
+func (h *PartnerHandler) Score(c *gin.Context) {
+	var req schemas.PartnerScoringRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierror.Abort(c, apierror.FromBind(err))
+		return
+	}
+
+	profile := models.FinancialProfile{
+		Income:             req.FinancialProfile.Income,
+		MonthlyPayments:    req.FinancialProfile.MonthlyPayments,
+		CreditHistory:      req.FinancialProfile.CreditHistory,
+		JobExperienceYears: req.FinancialProfile.JobExperienceYears,
+		Age:                req.FinancialProfile.Age,
+		IncomeProof:        req.FinancialProfile.IncomeProof,
+		Currency:           req.FinancialProfile.Currency,
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = money.BaseCurrency
+	}
+
+	result, err := services.CalculateColdScore(c.Request.Context(), h.FX, &profile, money.New(req.RequestedAmount, currency))
+	if errors.Is(err, money.ErrRateNotFound) {
+		apierror.Abort(c, apierror.ErrFXRateUnavailable.Wrap(err))
+		return
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to calculate score", err))
+		return
+	}
+	metrics.ObserveScoring(metrics.ProductPartnerAPI, result.Decision, result.TotalScore)
+
+	slog.InfoContext(c.Request.Context(), "Partner scoring", slog.String("external_id", req.ExternalID), slog.String("decision", result.Decision), slog.Int("score", result.TotalScore))
+
+	c.JSON(http.StatusOK, schemas.PartnerScoringResponse{
+		ExternalID:           req.ExternalID,
+		Decision:             result.Decision,
+		Score:                result.TotalScore,
+		DtiRatio:             result.DtiRatio,
+		RecommendedMaxAmount: result.RecommendedMaxAmount,
+		Currency:             result.Currency,
+		Reasons:              result.Recommendations,
+	})
+}
@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/events"
+	"ac-ai/internal/metrics"
 	"ac-ai/internal/models"
+	"ac-ai/internal/money"
 	"ac-ai/internal/repository"
 	"ac-ai/internal/schemas"
 	"ac-ai/internal/services"
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -17,17 +22,23 @@ type ScoringHandler struct {
 	AppRepo   *repository.ApplicationRepository
 	UserRepo  *repository.UserRepository
 	AIService *services.AIService
+	Events    events.Publisher
+	FX        services.FXRateProvider
 }
 
 func NewScoringHandler(
 	repo *repository.UserRepository,
 	appRepo *repository.ApplicationRepository,
 	ai *services.AIService,
+	publisher events.Publisher,
+	fx services.FXRateProvider,
 ) *ScoringHandler {
 	return &ScoringHandler{
 		UserRepo:  repo,
 		AppRepo:   appRepo,
 		AIService: ai,
+		Events:    publisher,
+		FX:        fx,
 	}
 }
 
@@ -36,41 +47,64 @@ func (h *ScoringHandler) Ask(c *gin.Context) {
 
 	// 1. Валидация запроса
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.Abort(c, apierror.FromBind(err))
 		return
 	}
 
+	// Контекст запроса несет спан трассы и отменяется, если клиент ушел
+	ctx := c.Request.Context()
+
 	// 2. Получаем ID юзера из middleware
 	userID, _ := c.Get("userID")
 
 	// 3. Получаем полный профиль юзера из БД
-	user, err := h.UserRepo.GetUserByID(userID.(uint))
+	user, err := h.UserRepo.WithContext(ctx).GetUserByID(userID.(uint))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User profile not found"})
+		apierror.Abort(c, apierror.ErrProfileNotFound)
 		return
 	}
 	if user.Role != models.RoleClient || user.FinancialProfile.ID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User is not a client or has no profile"})
+		apierror.Abort(c, apierror.ErrNotClient)
 		return
 	}
 
-	ctx := context.Background()
-
 	// 4. Парсим сумму из запроса
-	requestedAmount, err := h.AIService.ParseAmountFromQuery(ctx, req.Query)
-	if err != nil || requestedAmount == 0 {
+	requested, err := h.AIService.ParseAmountFromQuery(ctx, req.Query)
+	if errors.Is(err, money.ErrUnsupportedCurrency) {
+		answer := "К сожалению, мы выдаем кредиты только в тенге (KZT), долларах (USD) и рублях (RUB). Пожалуйста, укажите сумму в одной из этих валют."
+		c.JSON(http.StatusOK, schemas.ScoringResponse{Answer: answer})
+		return
+	}
+	if err != nil || requested.Amount == 0 {
 		answer := "Я могу помочь с расчетом кредита. Пожалуйста, укажите желаемую сумму, например: 'Хочу 15 000 000 тенге'."
 		c.JSON(http.StatusOK, schemas.ScoringResponse{Answer: answer})
 		return
 	}
 
+	// Валюта из интерфейса важнее распознанной в тексте; если нет ни той, ни другой - тенге
+	if req.Currency != "" {
+		requested.Currency = req.Currency
+	}
+	if requested.Currency == "" {
+		requested.Currency = money.BaseCurrency
+	}
+
 	// 5. "Холодный" скоринг
-	scoreResult := services.CalculateColdScore(&user.FinancialProfile, requestedAmount)
+	scoreResult, err := services.CalculateColdScore(ctx, h.FX, &user.FinancialProfile, requested)
+	if errors.Is(err, money.ErrRateNotFound) {
+		apierror.Abort(c, apierror.ErrFXRateUnavailable.Wrap(err))
+		return
+	}
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to calculate score", err))
+		return
+	}
+	metrics.ObserveScoring(metrics.ProductClientChat, scoreResult.Decision, scoreResult.TotalScore)
 
 	// 6. "Теплый" AI-анализ
 	answer, err := h.AIService.GetAIAnalysis(ctx, scoreResult, &user.FinancialProfile)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get AI analysis", "details": err.Error()})
+		apierror.Abort(c, apierror.ErrAIUnavailable.Wrap(err))
 		return
 	}
 	
@@ -78,13 +112,16 @@ func (h *ScoringHandler) Ask(c *gin.Context) {
 	internalReasonsStr := string(internalReasonsBytes)
 
 	application := models.ScoringApplication{
-		UserID:          user.ID,
-		RequestedAmount: requestedAmount,
-		FinalDecision:   scoreResult.Decision,
-		ColdScore:       scoreResult.TotalScore,
-		AIResponse:      answer,
-		InternalReasons: internalReasonsStr,
-		AgentStatus:     models.AgentStatusPending, // По умолчанию ждет
+		UserID:               user.ID,
+		RequestedAmount:      requested.Amount,
+		Currency:             requested.Currency,
+		RequestedAmountBase:  scoreResult.RequestedAmountBase,
+		FinalDecision:        scoreResult.Decision,
+		ColdScore:            scoreResult.TotalScore,
+		RecommendedMaxAmount: scoreResult.RecommendedMaxAmountBase,
+		AIResponse:           answer,
+		InternalReasons:      internalReasonsStr,
+		AgentStatus:          models.AgentStatusPending, // По умолчанию ждет
 	}
 
 	// Если решение НЕ ручное, то агенту не нужно ничего делать
@@ -92,10 +129,18 @@ func (h *ScoringHandler) Ask(c *gin.Context) {
 		application.AgentStatus = application.FinalDecision
 	}
 
-	// 8. Сохраняем в БД
-	if err := h.AppRepo.CreateApplication(&application); err != nil {
+	// 8. Сохраняем в БД. Ответ ИИ уже оплачен - сохраняем, даже если клиент отключился
+	if err := h.AppRepo.WithContext(context.WithoutCancel(ctx)).CreateApplication(&application); err != nil {
 		// Не показываем ошибку клиенту, но логируем ее
-		log.Printf("CRITICAL: Failed to save application for user %d: %v", user.ID, err)
+		slog.ErrorContext(ctx, "Failed to save application", slog.Any("amount", requested), slog.Any("error", err))
+	} else {
+		// Агенты видят новую заявку сразу, без опроса очереди
+		h.Events.Publish(events.Event{
+			Type:          events.ApplicationCreated,
+			ApplicationID: application.ID,
+			FinalDecision: application.FinalDecision,
+			AgentStatus:   application.AgentStatus,
+		})
 	}
 
 	// --- ** КОНЕЦ НОВОЙ ЛОГИКИ ** ---
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/auth"
+	"ac-ai/internal/models"
+	"ac-ai/internal/oidc"
+	"ac-ai/internal/repository"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Сколько пользователь может провести на странице входа IdP
+const oidcStateTTL = 10 * time.Minute
+
+// ssoStateCookie - хэш state в браузере, который начал вход. Без него чужую ссылку
+// на callback (злоумышленник прошел вход в IdP сам) можно подсунуть жертве,
+// и она войдет под учетной записью злоумышленника (login CSRF)
+const ssoStateCookie = "sso_state"
+
+// Приоритет ролей: при нескольких подходящих группах выдается самая старшая
+var ssoRolePriority = []string{models.RoleAdmin, models.RoleSupervisor, models.RoleAgent}
+
+type SSOHandler struct {
+	Auth     *AuthHandler
+	Provider *oidc.Provider
+	// Группа IdP -> роль сотрудника (AGENT, SUPERVISOR, ADMIN)
+	GroupRoles map[string]string
+	// Фронтенд, на который возвращается пользователь после входа
+	AppBaseURL string
+	// Cookie со state видна только callback-у; Secure, если callback по https
+	CookiePath   string
+	CookieSecure bool
+}
+
+func NewSSOHandler(authHandler *AuthHandler, provider *oidc.Provider, groupRoles map[string]string, appBaseURL string) *SSOHandler {
+	h := &SSOHandler{
+		Auth:       authHandler,
+		Provider:   provider,
+		GroupRoles: groupRoles,
+		AppBaseURL: strings.TrimRight(appBaseURL, "/"),
+		CookiePath: "/",
+	}
+	if u, err := url.Parse(provider.RedirectURL()); err == nil {
+		if u.Path != "" {
+			h.CookiePath = u.Path
+		}
+		h.CookieSecure = u.Scheme == "https"
+	}
+	return h
+}
+
+// GET /api/v1/auth/sso/login
+// Перенаправляет сотрудника на страницу входа корпоративного IdP
+func (h *SSOHandler) Login(c *gin.Context) {
+	state, stateHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to start SSO login", err))
+		return
+	}
+	nonce, err := auth.NewRandomID()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to start SSO login", err))
+		return
+	}
+	verifier, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to start SSO login", err))
+		return
+	}
+
+	if err := h.Auth.UserRepo.SaveOIDCState(stateHash, nonce, verifier, time.Now().Add(oidcStateTTL)); err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to start SSO login", err))
+		return
+	}
+
+	redirect, err := h.Provider.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		apierror.Abort(c, apierror.ErrIdentityUnavailable.Wrap(err))
+		return
+	}
+
+	// Lax: cookie уходит при переходе с IdP на callback (GET верхнего уровня)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoStateCookie, stateHash, int(oidcStateTTL.Seconds()), h.CookiePath, "", h.CookieSecure, true)
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// GET /api/v1/auth/sso/callback
+// IdP возвращает сюда code. Токены передаются фронтенду во фрагменте URL (#...),
+// который браузер не отправляет на сервер и не пишет в логи прокси
+func (h *SSOHandler) Callback(c *gin.Context) {
+	if idpErr := c.Query("error"); idpErr != "" {
+		slog.WarnContext(c.Request.Context(), "SSO: identity provider returned error", slog.String("idp_error", idpErr), slog.String("description", c.Query("error_description")))
+		h.redirectWithError(c, "access_denied")
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		h.redirectWithError(c, "invalid_request")
+		return
+	}
+
+	// state должен совпасть с cookie браузера, начавшего вход. Cookie одноразовая
+	stateHash := auth.HashOpaqueToken(state)
+	cookie, _ := c.Cookie(ssoStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoStateCookie, "", -1, h.CookiePath, "", h.CookieSecure, true)
+	if subtle.ConstantTimeCompare([]byte(cookie), []byte(stateHash)) != 1 {
+		slog.WarnContext(c.Request.Context(), "SSO: state does not match the browser that started the login", slog.String("category", "security"))
+		h.redirectWithError(c, "invalid_state")
+		return
+	}
+
+	loginState, err := h.Auth.UserRepo.ConsumeOIDCState(stateHash)
+	if err != nil {
+		if !errors.Is(err, repository.ErrOIDCStateInvalid) {
+			slog.ErrorContext(c.Request.Context(), "SSO: failed to load login state", slog.Any("error", err))
+		}
+		h.redirectWithError(c, "invalid_state")
+		return
+	}
+
+	identity, err := h.Provider.Exchange(c.Request.Context(), code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "SSO: code exchange failed", slog.Any("error", err))
+		h.redirectWithError(c, "invalid_grant")
+		return
+	}
+	if identity.Email == "" || !identity.EmailVerified {
+		slog.WarnContext(c.Request.Context(), "SSO: subject has no verified email", slog.String("subject", identity.Subject))
+		h.redirectWithError(c, "email_not_verified")
+		return
+	}
+
+	role := h.roleForGroups(identity.Groups)
+	if role == "" {
+		slog.WarnContext(c.Request.Context(), "SSO: subject has no group mapped to a staff role", slog.String("subject", identity.Subject))
+		h.redirectWithError(c, "no_staff_role")
+		return
+	}
+
+	user, err := h.Auth.UserRepo.ProvisionSSOUser(identity.Subject, identity.Email, role)
+	if err != nil {
+		if errors.Is(err, repository.ErrSSOAccountConflict) {
+			slog.WarnContext(c.Request.Context(), "SSO: cannot link subject to existing account", slog.String("subject", identity.Subject))
+			h.redirectWithError(c, "account_conflict")
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "SSO: failed to provision user", slog.Any("error", err))
+		h.redirectWithError(c, "server_error")
+		return
+	}
+	if user.Status != models.UserStatusActive {
+		h.redirectWithError(c, "account_inactive")
+		return
+	}
+
+	subject := identity.Subject
+	h.Auth.AuditRepo.Record(&models.AuditEvent{
+		Action:  models.AuditSSOProvisioned,
+		ActorID: &user.ID,
+		Subject: "sso:" + subject,
+		IP:      c.ClientIP(),
+		Details: "role " + role,
+	})
+
+	// Второй фактор: если IdP не подтвердил MFA (amr), действуют те же правила,
+	// что и при входе по паролю. Фронтенд продолжает через /auth/mfa/*
+	if purpose := h.Auth.mfaPurpose(user); purpose != "" && !identity.MFA() {
+		token, err := h.Auth.JWT.CreateChallengeToken(user.ID, user.Role, purpose)
+		if err != nil {
+			h.redirectWithError(c, "server_error")
+			return
+		}
+		fragment := url.Values{
+			"mfa_required":            {"true"},
+			"mfa_enrollment_required": {strconv.FormatBool(purpose == auth.PurposeMFAEnroll)},
+			"mfa_token":               {token},
+			"expires_in":              {strconv.Itoa(int(auth.ChallengeTokenTTL.Seconds()))},
+		}
+		c.Redirect(http.StatusFound, h.AppBaseURL+"/sso/callback#"+fragment.Encode())
+		return
+	}
+
+	tokens, err := h.Auth.newSession(user)
+	if err != nil {
+		h.redirectWithError(c, "server_error")
+		return
+	}
+
+	fragment := url.Values{
+		"access_token":  {tokens.AccessToken},
+		"refresh_token": {tokens.RefreshToken},
+		"token_type":    {tokens.TokenType},
+		"expires_in":    {strconv.Itoa(tokens.ExpiresIn)},
+	}
+	c.Redirect(http.StatusFound, h.AppBaseURL+"/sso/callback#"+fragment.Encode())
+}
+
+// roleForGroups - самая старшая роль среди групп пользователя ("" - не сотрудник)
+func (h *SSOHandler) roleForGroups(groups []string) string {
+	granted := map[string]bool{}
+	for _, group := range groups {
+		if role, ok := h.GroupRoles[group]; ok {
+			granted[role] = true
+		}
+	}
+	for _, role := range ssoRolePriority {
+		if granted[role] {
+			return role
+		}
+	}
+	return ""
+}
+
+func (h *SSOHandler) redirectWithError(c *gin.Context, code string) {
+	c.Redirect(http.StatusFound, h.AppBaseURL+"/sso/callback#"+url.Values{"error": {code}}.Encode())
+}
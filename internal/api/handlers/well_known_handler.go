@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"ac-ai/internal/auth"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WellKnownHandler struct {
+	Keys *auth.KeySet
+}
+
+func NewWellKnownHandler(keys *auth.KeySet) *WellKnownHandler {
+	return &WellKnownHandler{Keys: keys}
+}
+
+// GET /.well-known/jwks.json
+// Кэш короткий: после ротации новый ключ должен появиться у потребителей быстрее,
+// чем им начнут подписываться токены
+func (h *WellKnownHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": h.Keys.JWKS()})
+}
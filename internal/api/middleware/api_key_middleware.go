@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/auth"
+	"ac-ai/internal/logging"
+	"ac-ai/internal/repository"
+	"errors"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// APIKeyHeader - заголовок, в котором партнер передает ключ
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyMiddleware - аутентификация партнера по API-ключу с проверкой scope и лимита запросов
+func APIKeyMiddleware(repo *repository.APIKeyRepository, limiter *RateLimiter, scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawKey := c.GetHeader(APIKeyHeader)
+		if rawKey == "" {
+			apierror.Abort(c, apierror.ErrAPIKeyRequired)
+			return
+		}
+
+		key, err := repo.GetActiveAPIKey(auth.HashOpaqueToken(rawKey))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				apierror.Abort(c, apierror.ErrAPIKeyInvalid)
+				return
+			}
+			apierror.Abort(c, apierror.Internal("Failed to verify API key", err))
+			return
+		}
+
+		if !slices.Contains(key.ScopeList(), scope) {
+			apierror.Abort(c, apierror.ErrAPIKeyScope.With("scope", scope))
+			return
+		}
+
+		allowed, remaining, retryAfter := limiter.Allow(key.Prefix, key.RateLimit)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(key.RateLimit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			apierror.Abort(c, apierror.ErrRateLimited)
+			return
+		}
+
+		if err := repo.TouchAPIKey(key.ID); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update last use of API key",
+				slog.String("api_key", key.Prefix), slog.Any("error", err))
+		}
+
+		c.Set("apiKeyID", key.ID)
+		c.Set("apiKeyPrefix", key.Prefix)
+		c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(), slog.String("api_key", key.Prefix)))
+		c.Next()
+	}
+}
+
+// RateLimiter - token bucket на каждый ключ: limit запросов в минуту с допустимым всплеском до limit.
+// Счетчики в памяти инстанса, поэтому при N репликах фактический лимит до N*limit
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{buckets: make(map[string]*bucket)}
+}
+
+// Allow - забирает токен из корзины. Возвращает остаток и (при отказе) время до следующего токена
+func (l *RateLimiter) Allow(key string, perMinute int) (bool, int, time.Duration) {
+	if perMinute <= 0 {
+		return false, 0, time.Minute
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	rate := float64(perMinute) / time.Minute.Seconds() // токенов в секунду
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(perMinute), updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(perMinute), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+		return false, 0, wait
+	}
+	b.tokens--
+	return true, int(b.tokens), 0
+}
@@ -1,52 +1,127 @@
 package middleware
 
 import (
+	"ac-ai/internal/api/apierror"
 	"ac-ai/internal/auth"
-	"net/http"
+	"ac-ai/internal/logging"
+	"log/slog"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(jwtService *auth.JWTService) gin.HandlerFunc {
+func AuthMiddleware(jwtService *auth.JWTService, revocations auth.RevocationList) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
-			return
-		}
-
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid Bearer token format"})
+		tokenString, ok := bearerToken(c)
+		if !ok {
 			return
 		}
 
 		claims, err := jwtService.ValidateToken(tokenString)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token", "details": err.Error()})
+			apierror.Abort(c, apierror.ErrTokenInvalid.Wrap(err))
 			return
 		}
 
-		// Сохраняем ID юзера и роль в контексте Gin
-		c.Set("userID", claims.UserID)
-		c.Set("userRole", claims.Role)
+		if !setAccessClaims(c, claims, revocations) {
+			return
+		}
 		c.Next()
 	}
 }
 
-func RoleMiddleware(requiredRole string) gin.HandlerFunc {
+// MFAEnrollmentMiddleware - для подключения TOTP: принимает обычный access-токен
+// или challenge-токен mfa_enroll (сотрудник, которому MFA обязателен, еще не вошел)
+func MFAEnrollmentMiddleware(jwtService *auth.JWTService, revocations auth.RevocationList) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("userRole")
-		if !exists {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "User role not found in context"})
+		tokenString, ok := bearerToken(c)
+		if !ok {
 			return
 		}
 
-		if role.(string) != requiredRole {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Access forbidden: incorrect role"})
+		if claims, err := jwtService.ValidateToken(tokenString); err == nil {
+			if !setAccessClaims(c, claims, revocations) {
+				return
+			}
+			c.Next()
 			return
 		}
+
+		claims, err := jwtService.ValidateChallengeToken(tokenString, auth.PurposeMFAEnroll)
+		if err != nil {
+			apierror.Abort(c, apierror.ErrTokenInvalid.Wrap(err))
+			return
+		}
+		c.Set("userID", claims.UserID)
+		c.Set("userRole", claims.Role)
+		c.Set("mfaEnrollment", true)
+		setLogUser(c, claims.UserID, claims.Role)
+		c.Next()
+	}
+}
+
+func bearerToken(c *gin.Context) (string, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		apierror.Abort(c, apierror.ErrAuthRequired)
+		return "", false
+	}
+
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		apierror.Abort(c, apierror.ErrAuthHeader)
+		return "", false
+	}
+	return tokenString, true
+}
+
+func setAccessClaims(c *gin.Context, claims *auth.JWTClaims, revocations auth.RevocationList) bool {
+	// Без jti токен нельзя отозвать, без exp - нечем ограничить запись об отзыве.
+	// Все access-токены выпускаются с обоими; exp проверяет и KeySet.parse
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		apierror.Abort(c, apierror.ErrTokenInvalid)
+		return false
+	}
+
+	// Токен мог быть отозван через logout до истечения срока
+	revoked, err := revocations.IsRevoked(claims.ID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to verify token", err))
+		return false
+	}
+	if revoked {
+		apierror.Abort(c, apierror.ErrTokenRevoked)
+		return false
+	}
+
+	// Сохраняем ID юзера и роль в контексте Gin
+	c.Set("userID", claims.UserID)
+	c.Set("userRole", claims.Role)
+	c.Set("permissions", claims.Permissions)
+	// jti и срок токена нужны для logout
+	c.Set("tokenID", claims.ID)
+	c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+	setLogUser(c, claims.UserID, claims.Role)
+	return true
+}
+
+// setLogUser - user_id и role во всех логах оставшейся части запроса
+func setLogUser(c *gin.Context, userID uint, role string) {
+	c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(),
+		slog.Uint64("user_id", uint64(userID)), slog.String("role", role)))
+}
+
+// RequirePermission - пропускает, только если у пользователя есть ВСЕ перечисленные права
+func RequirePermission(required ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted := c.GetStringSlice("permissions")
+		for _, perm := range required {
+			if !slices.Contains(granted, perm) {
+				apierror.Abort(c, apierror.ErrPermission.With("permission", perm))
+				return
+			}
+		}
 		c.Next()
 	}
 }
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"ac-ai/internal/api/apierror"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrorMiddleware - единый ответ для ошибок, переданных через apierror.Abort (c.Error).
+// Внутренние ошибки (5xx) логируются с причиной, а клиент видит только код и
+// общее сообщение на языке из Accept-Language
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+		err := c.Errors.Last().Err
+		apiErr := apierror.From(err)
+
+		ctx := c.Request.Context()
+		if apiErr.Status >= http.StatusInternalServerError {
+			slog.ErrorContext(ctx, "Request failed", slog.String("code", string(apiErr.Code)), slog.Any("error", err))
+			trace.SpanFromContext(ctx).RecordError(err)
+		} else if cause := apiErr.Unwrap(); cause != nil {
+			slog.DebugContext(ctx, "Request rejected", slog.String("code", string(apiErr.Code)), slog.Any("error", cause))
+		}
+
+		// Хэндлер мог успеть начать ответ (потоковая выгрузка) - второй раз не пишем
+		if c.Writer.Written() {
+			return
+		}
+		lang := apierror.Language(c.GetHeader("Accept-Language"))
+		c.JSON(apiErr.Status, apiErr.Render(lang, c.GetString("requestID")))
+	}
+}
+
+// NoRouteHandler - 404 в том же формате, что и остальные ошибки
+func NoRouteHandler(c *gin.Context) {
+	apierror.Abort(c, apierror.ErrNotFound)
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"ac-ai/internal/api/apierror"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Пробы и сбор метрик идут каждые несколько секунд - пишем их только на уровне debug
+var quietRoutes = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// AccessLogMiddleware - одна структурированная запись на запрос (вместо логгера Gin).
+// Контекст берется после c.Next(), чтобы в записи были user_id и role из AuthMiddleware
+func AccessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		case quietRoutes[c.FullPath()]:
+			level = slog.LevelDebug
+		}
+
+		// Query не пишем: в нем бывают токены подтверждения и коды SSO
+		slog.LogAttrs(c.Request.Context(), level, "http request",
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(started).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// RecoveryMiddleware - паника в хэндлере логируется со стеком, клиент получает INTERNAL_ERROR без деталей
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				slog.ErrorContext(c.Request.Context(), "Panic recovered",
+					slog.Any("panic", rec), slog.String("stack", string(debug.Stack())))
+				apierror.Abort(c, apierror.Internal("panic", fmt.Errorf("%v", rec)))
+			}
+		}()
+		c.Next()
+	}
+}
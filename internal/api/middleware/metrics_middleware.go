@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"ac-ai/internal/metrics"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsMiddleware - счетчик и латентность запросов по шаблону маршрута
+// (/api/v1/agent/applications/:id), а не по фактическому пути, чтобы не плодить серии
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		if !knownMethods[method] {
+			method = "OTHER"
+		}
+		metrics.ObserveHTTP(method, route, strconv.Itoa(c.Writer.Status()), time.Since(started))
+	}
+}
+
+// Метод тоже приходит от клиента: произвольные значения сводим в OTHER
+var knownMethods = map[string]bool{
+	"GET": true, "HEAD": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "OPTIONS": true,
+}
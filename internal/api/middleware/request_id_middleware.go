@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"ac-ai/internal/logging"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader - идентификатор запроса для связи логов клиента, шлюза и API
+const RequestIDHeader = "X-Request-ID"
+
+// Принимаем ID от шлюза, только если он не может испортить логи
+var requestIDRe = regexp.MustCompile(`^[A-Za-z0-9._:\-]{1,128}$`)
+
+// RequestIDMiddleware берет X-Request-ID из запроса или генерирует новый,
+// возвращает его в ответе и добавляет request_id во все логи запроса
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !requestIDRe.MatchString(id) {
+			id = newRequestID()
+		}
+
+		c.Set("requestID", id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(), slog.String("request_id", id)))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
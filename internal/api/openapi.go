@@ -0,0 +1,365 @@
+// internal/api/openapi.go
+package api
+
+import (
+	"ac-ai/internal/api/apierror"
+	"ac-ai/internal/api/middleware"
+	"ac-ai/internal/auth"
+	"ac-ai/internal/models"
+	"ac-ai/internal/openapi"
+	"ac-ai/internal/schemas"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Ответы, которые хэндлеры собирают через gin.H. Нужны только для схемы
+type messageOut struct {
+	Message string `json:"message"`
+}
+
+type healthOut struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+type jwksOut struct {
+	Keys []auth.JWK `json:"keys"`
+}
+
+// ssoCallbackQuery - параметры, с которыми IdP возвращает сотрудника (c.Query в SSOHandler.Callback)
+type ssoCallbackQuery struct {
+	Code             string `form:"code"`
+	State            string `form:"state"`
+	Error            string `form:"error"`
+	ErrorDescription string `form:"error_description"`
+}
+
+// page - PaginatedResponse с конкретным типом элементов data
+type page[T any] struct {
+	Data []T                    `json:"data"`
+	Meta schemas.PaginationMeta `json:"meta"`
+}
+
+func permission(perm string) string {
+	return "Requires permission `" + perm + "`."
+}
+
+// apiRoutes - описание всех маршрутов SetupRouter. Маршрут без описания
+// роняет TestAPIRoutesDocumented, так что новый хэндлер без документации не смержить
+var apiRoutes = map[string]openapi.Route{
+	// --- Аутентификация ---
+	"POST /api/v1/auth/register": {
+		Tag: "auth", Summary: "Register a client",
+		Body: schemas.RegisterRequest{}, Status: http.StatusCreated, Response: schemas.UserOut{},
+	},
+	"POST /api/v1/auth/login": {
+		Tag: "auth", Summary: "Log in with email and password",
+		Description: "Returns tokens, or an MFA challenge when the account has a second factor (continue with /auth/mfa/verify).",
+		Body:        schemas.LoginRequest{}, Response: openapi.OneOf{schemas.LoginResponse{}, schemas.MFAChallengeResponse{}},
+	},
+	"POST /api/v1/auth/refresh": {
+		Tag: "auth", Summary: "Exchange a refresh token for a new token pair",
+		Body: schemas.RefreshRequest{}, Response: schemas.LoginResponse{},
+	},
+	"POST /api/v1/auth/accept-invite": {
+		Tag: "auth", Summary: "Accept a staff invite and set a password",
+		Body: schemas.AcceptInviteRequest{}, Response: schemas.StaffUserOut{},
+	},
+	"POST /api/v1/auth/logout": {
+		Tag: "auth", Summary: "Revoke the access token and, if given, the refresh token", Security: openapi.SecurityBearer,
+		Body: schemas.LogoutRequest{}, BodyOptional: true, Status: http.StatusNoContent,
+	},
+	"POST /api/v1/auth/verify-email": {
+		Tag: "auth", Summary: "Confirm email ownership with the token from the email",
+		Body: schemas.VerifyEmailRequest{}, Response: messageOut{},
+	},
+	"POST /api/v1/auth/verify-email/resend": {
+		Tag: "auth", Summary: "Send the verification email again", Security: openapi.SecurityBearer,
+		Status: http.StatusAccepted, Response: messageOut{},
+	},
+	"POST /api/v1/auth/forgot-password": {
+		Tag: "auth", Summary: "Send a password reset link",
+		Description: "Always responds 202, whether or not the email is registered.",
+		Body:        schemas.ForgotPasswordRequest{}, Status: http.StatusAccepted, Response: messageOut{},
+	},
+	"POST /api/v1/auth/reset-password": {
+		Tag: "auth", Summary: "Set a new password with the token from the email",
+		Body: schemas.ResetPasswordRequest{}, Response: messageOut{},
+	},
+	"POST /api/v1/auth/mfa/verify": {
+		Tag: "auth", Summary: "Complete login with a TOTP or recovery code",
+		Body: schemas.MFAVerifyRequest{}, Response: schemas.LoginResponse{},
+	},
+	"POST /api/v1/auth/mfa/totp/enroll": {
+		Tag: "auth", Summary: "Start TOTP enrollment", Security: openapi.SecurityBearer,
+		Description: "Accepts an access token or the mfa_token of an enrollment challenge.",
+		Response:    schemas.TOTPEnrollResponse{},
+	},
+	"POST /api/v1/auth/mfa/totp/confirm": {
+		Tag: "auth", Summary: "Confirm TOTP enrollment and get recovery codes", Security: openapi.SecurityBearer,
+		Body: schemas.TOTPConfirmRequest{}, Response: schemas.TOTPConfirmResponse{},
+	},
+	"GET /api/v1/auth/sso/login": {
+		Tag: "auth", Summary: "Redirect staff to the corporate identity provider",
+		Status: http.StatusFound,
+	},
+	"GET /api/v1/auth/sso/callback": {
+		Tag: "auth", Summary: "Identity provider callback",
+		Description: "Redirects to the frontend with tokens in the URL fragment, with an MFA challenge " +
+			"(mfa_required, mfa_token; continue with /auth/mfa/*) when a second factor is needed, or with error=... on failure.",
+		Query: ssoCallbackQuery{}, Status: http.StatusFound,
+	},
+
+	// --- Клиент ---
+	"POST /api/v1/scoring/ask": {
+		Tag: "scoring", Summary: "Ask for a credit decision", Security: openapi.SecurityBearer,
+		Description: permission(models.PermApplicationsCreate),
+		Body:        schemas.ScoringRequest{}, Response: schemas.ScoringResponse{},
+	},
+	"POST /api/v1/documents": {
+		Tag: "documents", Summary: "Upload an income document", Security: openapi.SecurityBearer,
+		Description: permission(models.PermDocumentsUpload) + " PDF, JPEG or PNG.",
+		Form:        schemas.DocumentUploadForm{}, Files: []openapi.File{{Name: "file", Required: true}},
+		Status: http.StatusCreated, Response: schemas.DocumentOut{},
+	},
+	"GET /api/v1/documents": {
+		Tag: "documents", Summary: "List my documents", Security: openapi.SecurityBearer,
+		Description: permission(models.PermDocumentsUpload),
+		Response:    []schemas.DocumentOut{},
+	},
+	"GET /api/v1/info-requests": {
+		Tag: "info-requests", Summary: "List information requests addressed to me", Security: openapi.SecurityBearer,
+		Description: permission(models.PermInfoRequestsAnswer),
+		Response:    []schemas.InfoRequestOut{},
+	},
+	"POST /api/v1/info-requests/:id/respond": {
+		Tag: "info-requests", Summary: "Answer an information request", Security: openapi.SecurityBearer,
+		Description: permission(models.PermInfoRequestsAnswer),
+		Form:        schemas.InfoResponseForm{}, Files: []openapi.File{{Name: "attachments", Multiple: true}},
+		Response: schemas.InfoRequestOut{},
+	},
+
+	// --- Кабинет агента ---
+	"GET /api/v1/agent/applications/review": {
+		Tag: "agent", Summary: "Applications waiting for manual review", Security: openapi.SecurityBearer,
+		Description: permission(models.PermApplicationsRead),
+		Query:       schemas.PaginationQuery{}, Response: page[schemas.ApplicationOut]{},
+	},
+	"GET /api/v1/agent/applications/all": {
+		Tag: "agent", Summary: "All applications", Security: openapi.SecurityBearer,
+		Description: permission(models.PermApplicationsRead),
+		Query:       schemas.PaginationQuery{}, Response: page[schemas.ApplicationOut]{},
+	},
+	"GET /api/v1/agent/clients": {
+		Tag: "agent", Summary: "All clients with financial profiles", Security: openapi.SecurityBearer,
+		Description: permission(models.PermClientsRead),
+		Query:       schemas.PaginationQuery{}, Response: page[schemas.ClientProfileOut]{},
+	},
+	"POST /api/v1/agent/applications/:id/decision": {
+		Tag: "agent", Summary: "Approve or deny an application", Security: openapi.SecurityBearer,
+		Description: permission(models.PermApplicationsDecide),
+		Body:        schemas.AgentDecisionRequest{}, Response: schemas.ApplicationOut{},
+	},
+	"POST /api/v1/agent/applications/:id/claim": {
+		Tag: "agent", Summary: "Claim an application for review", Security: openapi.SecurityBearer,
+		Description: permission(models.PermApplicationsDecide) + " Other agents cannot decide a claimed application. The claim expires after 30 minutes; claiming again extends it.",
+		Response:    schemas.ApplicationOut{},
+	},
+	"POST /api/v1/agent/applications/:id/release": {
+		Tag: "agent", Summary: "Return a claimed application to the queue", Security: openapi.SecurityBearer,
+		Description: permission(models.PermApplicationsDecide),
+		Response:    schemas.ApplicationOut{},
+	},
+	"POST /api/v1/agent/applications/:id/request-info": {
+		Tag: "agent", Summary: "Ask the client for a document or clarification", Security: openapi.SecurityBearer,
+		Description: permission(models.PermApplicationsDecide),
+		Body:        schemas.InfoRequestCreate{}, Status: http.StatusCreated, Response: schemas.InfoRequestOut{},
+	},
+	"GET /api/v1/agent/stats": {
+		Tag: "agent", Summary: "Dashboard statistics", Security: openapi.SecurityBearer,
+		Description: permission(models.PermStatsRead) + " Defaults to the last 30 days.",
+		Query:       schemas.StatsQuery{}, Response: schemas.StatsOut{},
+	},
+	"GET /api/v1/agent/events": {
+		Tag: "agent", Summary: "Review queue updates as Server-Sent Events", Security: openapi.SecurityBearer,
+		Description:  permission(models.PermApplicationsRead) + " Events: application.created, application.claimed, application.released, application.decided, application.info_requested, application.info_provided.",
+		ContentTypes: []string{"text/event-stream"},
+	},
+	"GET /api/v1/agent/clients/:id/documents": {
+		Tag: "documents", Summary: "List a client's documents", Security: openapi.SecurityBearer,
+		Description: permission(models.PermClientsRead),
+		Response:    []schemas.DocumentOut{},
+	},
+	"GET /api/v1/agent/documents/:id/file": {
+		Tag: "documents", Summary: "Download a document file", Security: openapi.SecurityBearer,
+		Description:  permission(models.PermDocumentsReview),
+		ContentTypes: []string{"application/pdf", "image/jpeg", "image/png"},
+	},
+	"POST /api/v1/agent/documents/:id/review": {
+		Tag: "documents", Summary: "Verify or reject a document", Security: openapi.SecurityBearer,
+		Description: permission(models.PermDocumentsReview),
+		Body:        schemas.DocumentReviewRequest{}, Response: schemas.DocumentOut{},
+	},
+	"GET /api/v1/agent/export/applications": {
+		Tag: "exports", Summary: "Export applications to CSV or XLSX", Security: openapi.SecurityBearer,
+		Description:  permission(models.PermExportsRun),
+		Query:        schemas.ApplicationExportQuery{},
+		ContentTypes: []string{"text/csv", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	},
+	"GET /api/v1/agent/export/clients": {
+		Tag: "exports", Summary: "Export clients to CSV or XLSX", Security: openapi.SecurityBearer,
+		Description:  permission(models.PermExportsRun),
+		Query:        schemas.ExportQuery{},
+		ContentTypes: []string{"text/csv", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	},
+
+	// --- Администрирование ---
+	"GET /api/v1/admin/staff": {
+		Tag: "admin", Summary: "List staff accounts", Security: openapi.SecurityBearer,
+		Description: permission(models.PermStaffManage),
+		Query:       schemas.PaginationQuery{}, Response: page[schemas.StaffUserOut]{},
+	},
+	"POST /api/v1/admin/staff/invite": {
+		Tag: "admin", Summary: "Invite a staff member", Security: openapi.SecurityBearer,
+		Description: permission(models.PermStaffManage),
+		Body:        schemas.StaffInviteRequest{}, Status: http.StatusCreated, Response: schemas.StaffInviteOut{},
+	},
+	"POST /api/v1/admin/staff/:id/activate": {
+		Tag: "admin", Summary: "Activate a staff account", Security: openapi.SecurityBearer,
+		Description: permission(models.PermStaffManage),
+		Response:    schemas.StaffUserOut{},
+	},
+	"POST /api/v1/admin/staff/:id/deactivate": {
+		Tag: "admin", Summary: "Deactivate a staff account and revoke its sessions", Security: openapi.SecurityBearer,
+		Description: permission(models.PermStaffManage),
+		Response:    schemas.StaffUserOut{},
+	},
+	"PUT /api/v1/admin/staff/:id/role": {
+		Tag: "admin", Summary: "Change a staff member's role", Security: openapi.SecurityBearer,
+		Description: permission(models.PermStaffManage),
+		Body:        schemas.StaffRoleRequest{}, Response: schemas.StaffUserOut{},
+	},
+	"POST /api/v1/admin/users/:id/unlock": {
+		Tag: "admin", Summary: "Clear the login lockout of a user", Security: openapi.SecurityBearer,
+		Description: permission(models.PermAccountsUnlock) + " Pass ip to also unlock that address.",
+		Body:        schemas.UnlockLoginRequest{}, BodyOptional: true, Status: http.StatusNoContent,
+	},
+	"GET /api/v1/admin/api-keys": {
+		Tag: "admin", Summary: "List partner API keys", Security: openapi.SecurityBearer,
+		Description: permission(models.PermAPIKeysManage),
+		Response:    []schemas.APIKeyOut{},
+	},
+	"POST /api/v1/admin/api-keys": {
+		Tag: "admin", Summary: "Create a partner API key", Security: openapi.SecurityBearer,
+		Description: permission(models.PermAPIKeysManage) + " The key is shown only once.",
+		Body:        schemas.APIKeyCreateRequest{}, Status: http.StatusCreated, Response: schemas.APIKeyCreatedOut{},
+	},
+	"DELETE /api/v1/admin/api-keys/:id": {
+		Tag: "admin", Summary: "Revoke a partner API key", Security: openapi.SecurityBearer,
+		Description: permission(models.PermAPIKeysManage),
+		Response:    schemas.APIKeyOut{},
+	},
+
+	// --- Партнеры ---
+	"POST /api/v1/partner/scoring": {
+		Tag: "partner", Summary: "Score an application without storing it", Security: openapi.SecurityAPIKey,
+		Description: "Requires API key scope `" + auth.ScopePartnerScoring + "`. Responses carry X-RateLimit-Limit and X-RateLimit-Remaining.",
+		Body:        schemas.PartnerScoringRequest{}, Response: schemas.PartnerScoringResponse{},
+	},
+
+	// --- Служебные ---
+	"GET /.well-known/jwks.json": {
+		Tag: "system", Summary: "Public keys for verifying access tokens",
+		Response: jwksOut{},
+	},
+	"GET /healthz": {
+		Tag: "system", Summary: "Liveness probe",
+		Response: healthOut{},
+	},
+	"GET /readyz": {
+		Tag: "system", Summary: "Readiness probe",
+		Description: "Responds 503 with the failed checks when the service should not receive traffic.",
+		Response:    healthOut{},
+	},
+	"GET /metrics": {
+		Tag: "system", Summary: "Prometheus metrics",
+		ContentTypes: []string{"text/plain"},
+	},
+	"GET /": {
+		Tag: "system", Summary: "Welcome message",
+		Response: messageOut{},
+	},
+	"GET /openapi.json": {
+		Tag: "system", Summary: "This OpenAPI document",
+		ContentTypes: []string{"application/json"},
+	},
+	"GET /docs": {
+		Tag: "system", Summary: "Swagger UI",
+		ContentTypes: []string{"text/html"},
+	},
+}
+
+func newAPISpec() *openapi.Spec {
+	g := openapi.NewGenerator()
+	g.Name(apierror.Response{}, "ErrorResponse")
+	g.Name(apierror.Body{}, "Error")
+	g.Name(apierror.FieldErrorMessage{}, "FieldError")
+
+	var codes []any
+	for _, code := range apierror.Codes() {
+		codes = append(codes, string(code))
+	}
+	g.Define(apierror.Code(""), &openapi.Schema{Type: "string", Enum: codes})
+	g.Define(gorm.DeletedAt{}, &openapi.Schema{Type: "string", Format: "date-time", Nullable: true})
+
+	return &openapi.Spec{
+		Info: openapi.Info{
+			Title:       "AC-AI Scoring API",
+			Version:     "1.0.0",
+			Description: "Errors use a common envelope: branch on error.code, not on the message. Messages follow Accept-Language (en, ru).",
+		},
+		Tags: []openapi.Tag{
+			{Name: "auth", Description: "Registration, login, tokens and MFA"},
+			{Name: "scoring", Description: "Credit decisions for clients"},
+			{Name: "documents", Description: "Income documents"},
+			{Name: "info-requests", Description: "Agent requests for additional information"},
+			{Name: "agent", Description: "Agent dashboard"},
+			{Name: "exports", Description: "CSV and XLSX exports"},
+			{Name: "admin", Description: "Staff and API key management"},
+			{Name: "partner", Description: "Partner integrations (API key)"},
+			{Name: "system", Description: "Probes, metrics and documentation"},
+		},
+		SecuritySchemes: map[string]openapi.SecurityScheme{
+			openapi.SecurityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			openapi.SecurityAPIKey: {Type: "apiKey", In: "header", Name: middleware.APIKeyHeader},
+		},
+		Routes:    apiRoutes,
+		Error:     apierror.Response{},
+		Generator: g,
+	}
+}
+
+// setupDocs регистрирует /openapi.json и /docs и строит спецификацию по уже
+// зарегистрированным маршрутам. Вызывается последним в SetupRouter
+func setupDocs(r *gin.Engine) {
+	var spec []byte
+	r.GET("/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+	})
+	r.GET("/docs", openapi.SwaggerUI("AC-AI Scoring API", "/openapi.json"))
+
+	doc, missing := newAPISpec().Build(r.Routes())
+	if len(missing) > 0 {
+		// Недокументированный маршрут ловит TestAPIRoutesDocumented; сервис из-за него не падает
+		slog.Error("OpenAPI: routes missing from apiRoutes (internal/api/openapi.go)", slog.Any("routes", missing))
+	}
+	var err error
+	if spec, err = json.Marshal(doc); err != nil {
+		slog.Error("OpenAPI: failed to encode spec", slog.Any("error", err))
+		spec = []byte(`{"error":"OpenAPI spec is unavailable"}`)
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"ac-ai/internal/auth"
+	"ac-ai/internal/config"
+	"ac-ai/internal/events"
+	"context"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Каждый маршрут SetupRouter описан в apiRoutes. Включены все необязательные
+// группы (SSO, /metrics на основном порту), чтобы проверка покрывала их тоже
+func TestAPIRoutesDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.JWTSecretKey = "test-secret"
+	cfg.MetricsPort = ""
+	// Discovery IdP ленивый: для регистрации маршрутов провайдер не нужен
+	cfg.OIDCIssuerURL = "https://idp.example.com"
+	cfg.OIDCRedirectURL = "https://api.example.com/api/v1/auth/sso/callback"
+
+	keys, err := auth.LoadKeySet(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// DryRun: маршрутам нужен *gorm.DB, но запросов при сборке роутера нет
+	db, err := gorm.Open(postgres.Open("host=localhost dbname=test"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := SetupRouter(ctx, db, cfg, events.NewMemoryBus(), keys, nil)
+
+	_, missing := newAPISpec().Build(r.Routes())
+	for _, route := range missing {
+		t.Errorf("route %s is missing from apiRoutes (internal/api/openapi.go)", route)
+	}
+}
@@ -5,17 +5,45 @@ import (
 	"ac-ai/internal/api/middleware"
 	"ac-ai/internal/auth"
 	"ac-ai/internal/config"
+	"ac-ai/internal/database"
+	"ac-ai/internal/events"
+	"ac-ai/internal/lockout"
+	"ac-ai/internal/mail"
+	"ac-ai/internal/metrics"
 	"ac-ai/internal/models"
+	"ac-ai/internal/oidc"
 	"ac-ai/internal/repository"
 	"ac-ai/internal/services"
+	"ac-ai/internal/storage"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
-	r := gin.Default()
+// SetupRouter собирает зависимости и маршруты. ctx отменяется при остановке сервера
+func SetupRouter(ctx context.Context, db *gorm.DB, cfg *config.Config, bus events.Bus, keys *auth.KeySet, migrator *database.Migrator) *gin.Engine {
+	// Вместо gin.Default: логгер и recovery Gin пишут текстом мимо slog
+	r := gin.New()
+	// По умолчанию Gin верит X-Forwarded-For от любого клиента, и блокировка
+	// входа по IP (c.ClientIP) обходится подменой заголовка
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		// Список проверен в config.Validate; на всякий случай не доверяем никому
+		slog.Error("Invalid TRUSTED_PROXIES, ignoring X-Forwarded-For", slog.Any("error", err))
+		r.SetTrustedProxies(nil)
+	}
+	r.Use(middleware.RequestIDMiddleware())
+	r.Use(middleware.TracingMiddleware())
+	r.Use(middleware.AccessLogMiddleware())
+	r.Use(middleware.MetricsMiddleware())
+	r.Use(middleware.ErrorMiddleware())
+	r.Use(middleware.RecoveryMiddleware())
+	r.NoRoute(middleware.NoRouteHandler)
+	registerValidators()
 
 	// ... (Настройка CORS) ...
 	r.Use(cors.New(cors.Config{
@@ -25,23 +53,54 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 			"http://localhost:3002",
 			"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "traceparent", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
 		AllowCredentials: true,
 	}))
 
 	// --- ОБНОВЛЕННАЯ ИНИЦИАЛИЗАЦИЯ ---
 	// Инициализация сервисов
 	userRepo := repository.NewUserRepository(db)
-	appRepo := repository.NewApplicationRepository(db) // <-- НОВЫЙ РЕПО
-	jwtService := auth.NewJWTService(cfg)
+	appRepo := repository.NewApplicationRepository(db, bus) // <-- НОВЫЙ РЕПО
+	jwtService := auth.NewJWTService(cfg, keys)
+	tokenRepo := repository.NewTokenRepository(db)
 	aiService := services.NewAIService(cfg)
+	docRepo := repository.NewDocumentRepository(db)
+	docService := services.NewDocumentService(
+		docRepo,
+		storage.NewLocalBlobStore(cfg.DocumentsDir),
+		storage.NoopScanner{}, // Подключить реальный антивирус здесь
+		cfg.MaxUploadSizeMB<<20,
+	)
+	auditRepo := repository.NewAuditRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	loginGuard := newLoginGuard(db, cfg)
+	fxRates := newFXRates(db, cfg)
+	accountEmails := services.NewAccountEmailService(
+		jwtService,
+		newMailer(cfg),
+		cfg.AppBaseURL,
+		time.Duration(cfg.EmailVerifyExpireHours)*time.Hour,
+		time.Duration(cfg.PasswordResetExpireMinutes)*time.Minute,
+	)
 
 	// Инициализация хэндлеров
-	authHandler := handlers.NewAuthHandler(userRepo, jwtService)
+	authHandler := handlers.NewAuthHandler(userRepo, tokenRepo, jwtService, time.Duration(cfg.JWTRefreshTokenExpireDays)*24*time.Hour, cfg.MFARequiredForStaff, loginGuard, auditRepo, accountEmails)
 	// Передаем appRepo в scoringHandler
-	scoringHandler := handlers.NewScoringHandler(userRepo, appRepo, aiService)
+	scoringHandler := handlers.NewScoringHandler(userRepo, appRepo, aiService, bus, fxRates)
 	agentHandler := handlers.NewAgentHandler(userRepo, appRepo) // <-- НОВЫЙ ХЭНДЛЕР
+	exportHandler := handlers.NewExportHandler(userRepo, appRepo)
+	adminHandler := handlers.NewAdminHandler(userRepo, tokenRepo, loginGuard, auditRepo)
+	eventsHandler := handlers.NewEventsHandler(ctx, bus)
+	documentHandler := handlers.NewDocumentHandler(docRepo, docService)
+	wellKnownHandler := handlers.NewWellKnownHandler(keys)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyRepo, cfg.APIKeyDefaultRateLimit)
+	partnerHandler := handlers.NewPartnerHandler(fxRates)
+	healthHandler := handlers.NewHealthHandler(db, migrator, nil)
+	if cfg.ReadyzCheckAI {
+		healthHandler.AI = aiService
+	}
+	infoRequestHandler := handlers.NewInfoRequestHandler(appRepo, docService, time.Duration(cfg.InfoRequestExpireHours)*time.Hour)
 
 	// Группа роутов
 	v1 := r.Group("/api/v1")
@@ -50,34 +109,191 @@ func SetupRouter(db *gorm.DB, cfg *config.Config) *gin.Engine {
 		{
 			authGroup.POST("/register", authHandler.Register)
 			authGroup.POST("/login", authHandler.Login)
+			authGroup.POST("/refresh", authHandler.Refresh)
+			authGroup.POST("/accept-invite", authHandler.AcceptInvite)
+			authGroup.POST("/logout", middleware.AuthMiddleware(jwtService, tokenRepo), authHandler.Logout)
+
+			// Подтверждение email и восстановление пароля по ссылкам из писем
+			authGroup.POST("/verify-email", authHandler.VerifyEmail)
+			authGroup.POST("/verify-email/resend", middleware.AuthMiddleware(jwtService, tokenRepo), authHandler.ResendVerification)
+			authGroup.POST("/forgot-password", authHandler.ForgotPassword)
+			authGroup.POST("/reset-password", authHandler.ResetPassword)
+
+			// Второй фактор (TOTP)
+			authGroup.POST("/mfa/verify", authHandler.VerifyMFA)
+			mfaEnrollment := middleware.MFAEnrollmentMiddleware(jwtService, tokenRepo)
+			authGroup.POST("/mfa/totp/enroll", mfaEnrollment, authHandler.EnrollTOTP)
+			authGroup.POST("/mfa/totp/confirm", mfaEnrollment, authHandler.ConfirmTOTP)
+
+			// Вход сотрудников через корпоративный IdP
+			if cfg.OIDCIssuerURL != "" {
+				provider := oidc.NewProvider(oidc.Config{
+					IssuerURL:    cfg.OIDCIssuerURL,
+					ClientID:     cfg.OIDCClientID,
+					ClientSecret: cfg.OIDCClientSecret,
+					RedirectURL:  cfg.OIDCRedirectURL,
+					Scopes:       strings.Fields(cfg.OIDCScopes),
+					GroupsClaim:  cfg.OIDCGroupsClaim,
+				})
+				ssoHandler := handlers.NewSSOHandler(authHandler, provider, cfg.OIDCGroupRoles, cfg.AppBaseURL)
+				authGroup.GET("/sso/login", ssoHandler.Login)
+				authGroup.GET("/sso/callback", ssoHandler.Callback)
+			}
 		}
 
 		scoringGroup := v1.Group("/scoring")
 		{
-			scoringGroup.Use(middleware.AuthMiddleware(jwtService))
-			scoringGroup.Use(middleware.RoleMiddleware(models.RoleClient))
-			scoringGroup.POST("/ask", scoringHandler.Ask)
+			scoringGroup.Use(middleware.AuthMiddleware(jwtService, tokenRepo))
+			scoringGroup.POST("/ask", middleware.RequirePermission(models.PermApplicationsCreate), scoringHandler.Ask)
+		}
+
+		// Документы клиента для подтверждения дохода
+		documentsGroup := v1.Group("/documents")
+		{
+			documentsGroup.Use(middleware.AuthMiddleware(jwtService, tokenRepo))
+			documentsGroup.Use(middleware.RequirePermission(models.PermDocumentsUpload))
+			documentsGroup.POST("", documentHandler.Upload)
+			documentsGroup.GET("", documentHandler.ListMine)
+		}
+
+		// Запросы информации от агента и ответы клиента
+		infoRequestsGroup := v1.Group("/info-requests")
+		{
+			infoRequestsGroup.Use(middleware.AuthMiddleware(jwtService, tokenRepo))
+			infoRequestsGroup.Use(middleware.RequirePermission(models.PermInfoRequestsAnswer))
+			infoRequestsGroup.GET("", infoRequestHandler.ListMine)
+			infoRequestsGroup.POST("/:id/respond", infoRequestHandler.Respond)
 		}
 
 		// --- НОВЫЙ БЛОК: КАБИНЕТ АГЕНТА ---
 		agentGroup := v1.Group("/agent")
 		{
-			agentGroup.Use(middleware.AuthMiddleware(jwtService))
-			agentGroup.Use(middleware.RoleMiddleware(models.RoleAgent))
+			agentGroup.Use(middleware.AuthMiddleware(jwtService, tokenRepo))
+
+			canRead := middleware.RequirePermission(models.PermApplicationsRead)
+			canDecide := middleware.RequirePermission(models.PermApplicationsDecide)
+			canReadClients := middleware.RequirePermission(models.PermClientsRead)
+			canReviewDocuments := middleware.RequirePermission(models.PermDocumentsReview)
+			canExport := middleware.RequirePermission(models.PermExportsRun)
 
 			// Дашборд: Заявки на ручное рассмотрение
-			agentGroup.GET("/applications/review", agentHandler.GetApplicationsForReview)
+			agentGroup.GET("/applications/review", canRead, agentHandler.GetApplicationsForReview)
 			// Мониторинг: Все клиенты
-			agentGroup.GET("/clients", agentHandler.GetAllClients)
+			agentGroup.GET("/clients", canReadClients, agentHandler.GetAllClients)
 			// Мониторинг: Все заявки
-			agentGroup.GET("/applications/all", agentHandler.GetAllApplications)
+			agentGroup.GET("/applications/all", canRead, agentHandler.GetAllApplications)
 			// Мониторинг: Все клиенты
+
+			// Ручное решение по заявке из очереди
+			agentGroup.POST("/applications/:id/decision", canDecide, agentHandler.DecideApplication)
+			// Взять заявку в работу / вернуть в очередь
+			agentGroup.POST("/applications/:id/claim", canDecide, agentHandler.ClaimApplication)
+			agentGroup.POST("/applications/:id/release", canDecide, agentHandler.ReleaseApplication)
+			// Вместо решения - запросить у клиента документ или пояснение
+			agentGroup.POST("/applications/:id/request-info", canDecide, infoRequestHandler.RequestInfo)
+			// Дашборд: Статистика
+			agentGroup.GET("/stats", middleware.RequirePermission(models.PermStatsRead), agentHandler.GetStats)
+			// Real-time обновления очереди (Server-Sent Events)
+			agentGroup.GET("/events", canRead, eventsHandler.Stream)
+
+			// Проверка документов клиентов
+			agentGroup.GET("/clients/:id/documents", canReadClients, documentHandler.ListByClient)
+			agentGroup.GET("/documents/:id/file", canReviewDocuments, documentHandler.Download)
+			agentGroup.POST("/documents/:id/review", canReviewDocuments, documentHandler.Review)
+
+			// Выгрузка в CSV/XLSX для риск-менеджмента
+			agentGroup.GET("/export/applications", canExport, exportHandler.ExportApplications)
+			agentGroup.GET("/export/clients", canExport, exportHandler.ExportClients)
+		}
+
+		// --- АДМИНИСТРИРОВАНИЕ СОТРУДНИКОВ ---
+		adminGroup := v1.Group("/admin")
+		{
+			adminGroup.Use(middleware.AuthMiddleware(jwtService, tokenRepo))
+
+			canManageStaff := middleware.RequirePermission(models.PermStaffManage)
+
+			adminGroup.GET("/staff", canManageStaff, adminHandler.ListStaff)
+			adminGroup.POST("/staff/invite", canManageStaff, adminHandler.InviteStaff)
+			adminGroup.POST("/staff/:id/activate", canManageStaff, adminHandler.ActivateStaff)
+			adminGroup.POST("/staff/:id/deactivate", canManageStaff, adminHandler.DeactivateStaff)
+			adminGroup.PUT("/staff/:id/role", canManageStaff, adminHandler.ChangeStaffRole)
+
+			// Снятие блокировки входа после перебора паролей
+			adminGroup.POST("/users/:id/unlock", middleware.RequirePermission(models.PermAccountsUnlock), adminHandler.UnlockLogin)
+
+			// API-ключи партнеров
+			canManageAPIKeys := middleware.RequirePermission(models.PermAPIKeysManage)
+			adminGroup.GET("/api-keys", canManageAPIKeys, apiKeyHandler.List)
+			adminGroup.POST("/api-keys", canManageAPIKeys, apiKeyHandler.Create)
+			adminGroup.DELETE("/api-keys/:id", canManageAPIKeys, apiKeyHandler.Revoke)
+		}
+
+		// --- ПАРТНЕРСКИЕ ИНТЕГРАЦИИ (по API-ключу, без JWT) ---
+		partnerGroup := v1.Group("/partner")
+		{
+			apiKeyLimiter := middleware.NewRateLimiter()
+			partnerGroup.POST("/scoring", middleware.APIKeyMiddleware(apiKeyRepo, apiKeyLimiter, auth.ScopePartnerScoring), partnerHandler.Score)
 		}
 	}
 
+	// Публичные ключи для проверки наших токенов другими сервисами
+	r.GET("/.well-known/jwks.json", wellKnownHandler.JWKS)
+
+	// Пробы оркестратора: вне /api/v1 и без авторизации
+	r.GET("/healthz", healthHandler.Live)
+	r.GET("/readyz", healthHandler.Ready)
+	if cfg.MetricsPort == "" {
+		r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "Welcome to AC-AI Scoring API (Go Version)"})
 	})
 
+	// Документация API: /openapi.json и Swagger UI на /docs
+	setupDocs(r)
+
 	return r
 }
+
+// newLoginGuard - счетчики неудачных входов в памяти (один инстанс) или в Postgres (несколько реплик)
+func newLoginGuard(db *gorm.DB, cfg *config.Config) *lockout.Guard {
+	var store lockout.Store
+	switch cfg.LoginLockoutStore {
+	case "postgres":
+		store = lockout.NewPostgresStore(db)
+	default:
+		store = lockout.NewMemoryStore()
+	}
+
+	return lockout.NewGuard(store, lockout.Policy{
+		MaxAccountFailures: cfg.LoginMaxAccountFailures,
+		MaxIPFailures:      cfg.LoginMaxIPFailures,
+		BaseLockout:        time.Duration(cfg.LoginLockoutBaseSeconds) * time.Second,
+		MaxLockout:         time.Duration(cfg.LoginLockoutMaxMinutes) * time.Minute,
+		Window:             time.Duration(cfg.LoginFailureWindowMinutes) * time.Minute,
+	})
+}
+
+// newFXRates - курсы из таблицы fx_rates или из JSON-файла
+func newFXRates(db *gorm.DB, cfg *config.Config) services.FXRateProvider {
+	switch cfg.FXRatesBackend {
+	case "postgres":
+		return repository.NewFXRateRepository(db)
+	default:
+		return services.NewFileFXRates(cfg.FXRatesFile)
+	}
+}
+
+// newMailer - SMTP в проде, файлы .eml или лог при локальной разработке
+func newMailer(cfg *config.Config) mail.Mailer {
+	switch cfg.MailerBackend {
+	case "smtp":
+		return mail.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	case "file":
+		return mail.NewFileMailer(cfg.MailOutboxDir, cfg.MailFrom)
+	default:
+		return mail.LogMailer{}
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"ac-ai/internal/auth"
+	"ac-ai/internal/money"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// registerValidators - собственные правила для тегов binding в schemas
+func registerValidators() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// В ошибках валидации - имена полей из JSON (или формы), а не Go-структур
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return f.Name
+	})
+
+	// binding:"password" - парольная политика (auth.ConfigurePasswords)
+	v.RegisterValidation("password", func(fl validator.FieldLevel) bool {
+		return auth.CheckPasswordPolicy(fl.Field().String()) == nil
+	})
+
+	// binding:"currency" - код поддерживаемой валюты (KZT, USD, RUB)
+	v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return money.Currency(fl.Field().String()).Supported()
+	})
+}
@@ -0,0 +1,239 @@
+package auth
+
+import (
+	"ac-ai/internal/config"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey - ключ подписи JWT. У ключей "только для проверки" signKey == nil
+type SigningKey struct {
+	ID        string // kid в заголовке токена (имя PEM-файла без расширения)
+	Method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// KeySet - активный ключ подписи и предыдущие ключи, которые еще принимаются при проверке.
+// Токен, подписанный не активным ключом, принимается, только если выпущен не раньше,
+// чем grace назад: все такие токены выпущены до ротации, поэтому после grace они отсекаются
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+	// legacy - общий секрет HS256 для токенов без kid, выпущенных до перехода на асимметричные ключи
+	legacy *SigningKey
+	// legacyUntil - JWT_LEGACY_ACCEPT_UNTIL: одна дата для всех реплик и рестартов.
+	// Срок не считается от iat токена: iat подписан тем же секретом,
+	// и знающий секрет проставил бы iat=now
+	legacyUntil time.Time
+	grace       time.Duration
+}
+
+// LoadKeySet - без JWT_KEYS_DIR работает как раньше: HS256 на JWT_SECRET_KEY.
+// С JWT_KEYS_DIR подписывает ключом JWT_ACTIVE_KID (RS256 или EdDSA по типу ключа),
+// остальные *.pem в директории - предыдущие ключи для проверки
+func LoadKeySet(cfg *config.Config) (*KeySet, error) {
+	var secret *SigningKey
+	if cfg.JWTSecretKey != "" {
+		secret = &SigningKey{
+			Method:    jwt.SigningMethodHS256,
+			signKey:   []byte(cfg.JWTSecretKey),
+			verifyKey: []byte(cfg.JWTSecretKey),
+		}
+	}
+
+	grace := time.Duration(cfg.JWTKeyGraceHours) * time.Hour
+
+	if cfg.JWTKeysDir == "" {
+		if secret == nil {
+			return nil, errors.New("JWT_SECRET_KEY or JWT_KEYS_DIR must be set")
+		}
+		return &KeySet{active: secret, keys: map[string]*SigningKey{}, grace: grace}, nil
+	}
+
+	// Старые токены без kid - только до JWT_LEGACY_ACCEPT_UNTIL
+	var legacy *SigningKey
+	if !cfg.JWTLegacyAcceptUntil.IsZero() && time.Now().Before(cfg.JWTLegacyAcceptUntil) {
+		legacy = secret
+	}
+
+	paths, err := filepath.Glob(filepath.Join(cfg.JWTKeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{keys: make(map[string]*SigningKey), legacy: legacy, legacyUntil: cfg.JWTLegacyAcceptUntil, grace: grace}
+	for _, path := range paths {
+		key, err := loadPEMKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("load JWT key %s: %w", path, err)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	ks.active = ks.keys[cfg.JWTActiveKID]
+	if ks.active == nil {
+		return nil, fmt.Errorf("active JWT key %q not found in %s", cfg.JWTActiveKID, cfg.JWTKeysDir)
+	}
+	if ks.active.signKey == nil {
+		return nil, fmt.Errorf("active JWT key %q has no private key", cfg.JWTActiveKID)
+	}
+	return ks, nil
+}
+
+func loadPEMKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	key := &SigningKey{ID: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T (want RSA or Ed25519)", parsed)
+	}
+
+	if rsaKey, ok := key.verifyKey.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return nil, errors.New("RSA key must be at least 2048 bits")
+	}
+	return key, nil
+}
+
+// sign - подписывает активным ключом и проставляет kid
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	if ks.active.ID != "" {
+		token.Header["kid"] = ks.active.ID
+	}
+	return token.SignedString(ks.active.signKey)
+}
+
+// parse - проверяет подпись ключом из kid и срок действия старых ключей.
+// Алгоритм закреплен за ключом, exp обязателен: токен без срока не принимается
+func (ks *KeySet) parse(tokenString string, claims *JWTClaims) error {
+	// kid нужен до проверки подписи, чтобы выбрать ключ и его алгоритм
+	unverified, _, err := jwt.NewParser().ParseUnverified(tokenString, &JWTClaims{})
+	if err != nil {
+		return err
+	}
+	kid, _ := unverified.Header["kid"].(string)
+	key, err := ks.keyFor(kid)
+	if err != nil {
+		return err
+	}
+
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+		return key.verifyKey, nil
+	}, jwt.WithValidMethods([]string{key.Method.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return err
+	}
+
+	// Для legacy срок уже ограничен legacyUntil, iat от его владельца ничего не доказывает
+	if key != ks.active && key != ks.legacy {
+		if claims.IssuedAt == nil || time.Since(claims.IssuedAt.Time) > ks.grace {
+			return errors.New("token signed with a retired key")
+		}
+	}
+	return nil
+}
+
+// keyFor - ключ проверки по kid. Пустой kid - активный HS256 или legacy-секрет
+func (ks *KeySet) keyFor(kid string) (*SigningKey, error) {
+	if kid == ks.active.ID {
+		return ks.active, nil
+	}
+	if kid == "" {
+		if ks.legacy == nil {
+			return nil, errors.New("tokens signed with JWT_SECRET_KEY are not accepted")
+		}
+		if !time.Now().Before(ks.legacyUntil) {
+			return nil, errors.New("tokens signed with JWT_SECRET_KEY are no longer accepted")
+		}
+		return ks.legacy, nil
+	}
+	key := ks.keys[kid]
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// JWK - публичный ключ в формате RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS - публичные ключи для проверки наших токенов другими сервисами.
+// HS256-секрет сюда, разумеется, не попадает
+func (ks *KeySet) JWKS() []JWK {
+	jwks := []JWK{}
+	for _, key := range ks.keys {
+		jwk := JWK{Kid: key.ID, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		jwks = append(jwks, jwk)
+	}
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].Kid < jwks[j].Kid })
+	return jwks
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// NewOpaqueToken - случайный непрозрачный токен (256 бит, base64url) и его хэш для
+// хранения в БД. Используется для refresh-токенов, приглашений, state и PKCE verifier в SSO
+func NewOpaqueToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashOpaqueToken(token), nil
+}
+
+// HashOpaqueToken - хэш для поиска токена в БД. Хэшируются только случайные
+// значения (токены, коды восстановления), поэтому достаточно SHA-256 (bcrypt не нужен)
+func HashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewRandomID - идентификатор для jti и семьи refresh-токенов
+func NewRandomID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Префикс ключей партнеров: по нему ключ легко найти в логах и сканерах утечек
+const apiKeyPrefix = "acai_"
+
+// NewAPIKey - ключ партнера вида acai_<prefix>_<secret>. prefix показывается в списках
+// и помогает опознать ключ, сам ключ выдается один раз, а в БД хранится только его хэш
+func NewAPIKey() (key, prefix, hash string, err error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	secret, _, err := NewOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	prefix = apiKeyPrefix + hex.EncodeToString(id)
+	key = prefix + "_" + secret
+	return key, prefix, HashOpaqueToken(key), nil
+}
@@ -1,13 +1,167 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"ac-ai/internal/config"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Алгоритмы хэширования паролей
+const (
+	PasswordAlgBcrypt   = "bcrypt"
+	PasswordAlgArgon2id = "argon2id"
+)
+
+// PasswordParams - параметры хэширования новых паролей.
+// Алгоритм и параметры хранятся в самом хэше ($2a$<cost>$... или PHC-строка $argon2id$v=19$m=...,t=...,p=...$),
+// поэтому старые хэши проверяются и после смены настроек, а при входе пересчитываются
+type PasswordParams struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2MemoryKB    uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
+
+// passwordParams - задаются один раз при старте (ConfigurePasswords)
+var passwordParams = PasswordParams{
+	Algorithm:  PasswordAlgBcrypt,
+	BcryptCost: 12,
+}
+
+// ConfigurePasswords - параметры хэширования и парольная политика из конфига
+func ConfigurePasswords(cfg *config.Config) error {
+	params := PasswordParams{
+		Algorithm:         cfg.PasswordHashAlgorithm,
+		BcryptCost:        cfg.BcryptCost,
+		Argon2MemoryKB:    uint32(cfg.Argon2MemoryKB),
+		Argon2Iterations:  uint32(cfg.Argon2Iterations),
+		Argon2Parallelism: uint8(cfg.Argon2Parallelism),
+	}
+	switch params.Algorithm {
+	case PasswordAlgBcrypt:
+		if params.BcryptCost < bcrypt.MinCost || params.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("BCRYPT_COST must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case PasswordAlgArgon2id:
+		if params.Argon2MemoryKB < 8*uint32(params.Argon2Parallelism) || params.Argon2Iterations < 1 || params.Argon2Parallelism < 1 {
+			return errors.New("invalid argon2id parameters")
+		}
+	default:
+		return fmt.Errorf("unknown PASSWORD_HASH_ALGORITHM %q", params.Algorithm)
+	}
+	passwordParams = params
+
+	policy, err := LoadPasswordPolicy(cfg.PasswordMinLength, cfg.PasswordBreachedListFile)
+	if err != nil {
+		return err
+	}
+	passwordPolicy = policy
+	return nil
+}
 
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	if passwordParams.Algorithm == PasswordAlgArgon2id {
+		return hashArgon2id(password, passwordParams)
+	}
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), passwordParams.BcryptCost)
 	return string(bytes), err
 }
 
 func CheckPasswordHash(password, hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		return checkArgon2id(password, hash)
+	}
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
-}
\ No newline at end of file
+}
+
+// PasswordNeedsRehash - хэш посчитан другим алгоритмом или с другими параметрами
+func PasswordNeedsRehash(hash string) bool {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		if passwordParams.Algorithm != PasswordAlgArgon2id {
+			return true
+		}
+		p, _, _, err := decodeArgon2id(hash)
+		return err != nil ||
+			p.Argon2MemoryKB != passwordParams.Argon2MemoryKB ||
+			p.Argon2Iterations != passwordParams.Argon2Iterations ||
+			p.Argon2Parallelism != passwordParams.Argon2Parallelism
+	}
+
+	if passwordParams.Algorithm != PasswordAlgBcrypt {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != passwordParams.BcryptCost
+}
+
+func hashArgon2id(password string, p PasswordParams) (string, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Argon2Iterations, p.Argon2MemoryKB, p.Argon2Parallelism, argon2KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Argon2MemoryKB, p.Argon2Iterations, p.Argon2Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func checkArgon2id(password, hash string) bool {
+	p, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false
+	}
+	computed := argon2.IDKey([]byte(password), salt, p.Argon2Iterations, p.Argon2MemoryKB, p.Argon2Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(computed, key) == 1
+}
+
+// decodeArgon2id - разбор PHC-строки $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func decodeArgon2id(hash string) (PasswordParams, []byte, []byte, error) {
+	var p PasswordParams
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errors.New("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Argon2MemoryKB, &p.Argon2Iterations, &p.Argon2Parallelism); err != nil {
+		return p, nil, nil, errors.New("invalid argon2id parameters")
+	}
+	// argon2.IDKey паникует при t=0 или p=0
+	if p.Argon2Iterations < 1 || p.Argon2Parallelism < 1 {
+		return p, nil, nil, errors.New("invalid argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, err
+	}
+	// Короткий ключ из поврежденной записи ослабил бы сравнение в checkArgon2id
+	if len(key) != argon2KeyLen || len(salt) < argon2SaltLen {
+		return p, nil, nil, errors.New("invalid argon2id salt or key length")
+	}
+	p.Algorithm = PasswordAlgArgon2id
+	return p, salt, key, nil
+}
@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrPasswordTooShort = errors.New("password is too short")
+	ErrPasswordTooLong  = errors.New("password is too long")
+	ErrPasswordBreached = errors.New("password appears in a list of breached passwords")
+)
+
+// bcrypt учитывает только первые 72 байта - длиннее не принимаем, чтобы не было ложного ощущения стойкости
+const maxPasswordBytes = 72
+
+// PasswordPolicy - минимальная длина и список утекших паролей
+type PasswordPolicy struct {
+	MinLength int
+	breached  map[[sha1.Size]byte]struct{}
+}
+
+var passwordPolicy = &PasswordPolicy{MinLength: 8}
+
+// LoadPasswordPolicy - список утекших паролей: по одному в строке, открытым текстом
+// или SHA-1 в hex (формат Have I Been Pwned "HASH:count" тоже подходит).
+// Список целиком держится в памяти, поэтому рассчитан на топ-N самых частых паролей
+func LoadPasswordPolicy(minLength int, breachedListFile string) (*PasswordPolicy, error) {
+	policy := &PasswordPolicy{MinLength: minLength, breached: map[[sha1.Size]byte]struct{}{}}
+	if breachedListFile == "" {
+		return policy, nil
+	}
+
+	f, err := os.Open(breachedListFile)
+	if err != nil {
+		return nil, fmt.Errorf("open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var sum [sha1.Size]byte
+		hexPart, _, _ := strings.Cut(line, ":")
+		if decoded, err := hex.DecodeString(hexPart); err == nil && len(decoded) == sha1.Size {
+			copy(sum[:], decoded)
+		} else {
+			sum = sha1.Sum([]byte(line))
+		}
+		policy.breached[sum] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read breached password list: %w", err)
+	}
+	return policy, nil
+}
+
+// Check - пароль соответствует политике
+func (p *PasswordPolicy) Check(password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return ErrPasswordTooShort
+	}
+	if len(password) > maxPasswordBytes {
+		return ErrPasswordTooLong
+	}
+	if _, ok := p.breached[sha1.Sum([]byte(password))]; ok {
+		return ErrPasswordBreached
+	}
+	return nil
+}
+
+// CheckPasswordPolicy - проверка по политике, заданной в ConfigurePasswords
+func CheckPasswordPolicy(password string) error {
+	return passwordPolicy.Check(password)
+}
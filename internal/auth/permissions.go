@@ -0,0 +1,48 @@
+package auth
+
+import "ac-ai/internal/models"
+
+// Права (scopes) API-ключей партнеров. Выдаются ключу, а не роли
+const (
+	ScopePartnerScoring = "partner:scoring"
+)
+
+// DefaultRolePermissions - права ролей по умолчанию.
+// Любую роль можно переопределить через ROLE_PERMISSIONS в конфиге
+var DefaultRolePermissions = map[string][]string{
+	models.RoleClient: {models.PermApplicationsCreate, models.PermDocumentsUpload, models.PermInfoRequestsAnswer},
+	models.RoleAgent: {
+		models.PermApplicationsRead, models.PermApplicationsDecide, models.PermClientsRead,
+		models.PermDocumentsReview, models.PermStatsRead, models.PermExportsRun,
+	},
+	// Супервайзер видит все и делает выгрузки, но сам не принимает решений
+	models.RoleSupervisor: {models.PermApplicationsRead, models.PermClientsRead, models.PermStatsRead, models.PermExportsRun},
+	models.RoleAdmin: {
+		models.PermApplicationsRead, models.PermApplicationsDecide, models.PermClientsRead,
+		models.PermDocumentsReview, models.PermStatsRead, models.PermExportsRun, models.PermStaffManage,
+		models.PermAccountsUnlock, models.PermAPIKeysManage,
+	},
+}
+
+// Policy - отображение "роль -> набор прав"
+type Policy struct {
+	roles map[string][]string
+}
+
+// NewPolicy - права по умолчанию, поверх которых применяются переопределения из конфига
+func NewPolicy(overrides map[string][]string) *Policy {
+	roles := make(map[string][]string, len(DefaultRolePermissions))
+	for role, perms := range DefaultRolePermissions {
+		roles[role] = perms
+	}
+	// Роли и права в overrides проверены в config.Validate
+	for role, perms := range overrides {
+		roles[role] = perms
+	}
+	return &Policy{roles: roles}
+}
+
+// Permissions - права роли (пустой список для неизвестной роли)
+func (p *Policy) Permissions(role string) []string {
+	return p.roles[role]
+}
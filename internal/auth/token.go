@@ -2,6 +2,10 @@ package auth
 
 import (
 	"ac-ai/internal/config"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,51 +13,177 @@ import (
 )
 
 type JWTService struct {
-	secretKey     string
+	// actionKey - ключ отпечатка в токенах из писем (ACTION_TOKEN_SECRET или JWT_SECRET_KEY)
+	actionKey     string
+	keys          *KeySet
 	expireMinutes time.Duration
+	policy        *Policy
 }
 
 type JWTClaims struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
+	UserID      uint     `json:"user_id"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+	// Purpose не пустой у промежуточных токенов второго фактора - они не дают доступа к API
+	Purpose string `json:"purpose,omitempty"`
+	// Fingerprint - отпечаток состояния пользователя для одноразовых токенов из писем
+	Fingerprint string `json:"fp,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func NewJWTService(cfg *config.Config) *JWTService {
+// Назначения промежуточных (challenge) токенов при входе с MFA
+const (
+	PurposeMFAVerify = "mfa_verify" // Пароль верный, нужен TOTP-код или код восстановления
+	PurposeMFAEnroll = "mfa_enroll" // Пароль верный, но сначала нужно подключить TOTP
+)
+
+// Назначения одноразовых токенов, которые отправляются по email
+const (
+	PurposeEmailVerify   = "email_verify"
+	PurposePasswordReset = "password_reset"
+)
+
+// Время жизни challenge-токена: только на ввод кода
+const ChallengeTokenTTL = 5 * time.Minute
+
+func NewJWTService(cfg *config.Config, keys *KeySet) *JWTService {
+	actionKey := cfg.ActionTokenSecret
+	if actionKey == "" {
+		actionKey = cfg.JWTSecretKey
+	}
 	return &JWTService{
-		secretKey:     cfg.JWTSecretKey,
-		expireMinutes: cfg.JWTAccessTokenExpireMinutes * time.Minute,
+		actionKey:     actionKey,
+		keys:          keys,
+		expireMinutes: time.Duration(cfg.JWTAccessTokenExpireMinutes) * time.Minute,
+		policy:        NewPolicy(cfg.RolePermissions),
+	}
+}
+
+// RevocationList - проверка отозванных access-токенов по jti
+type RevocationList interface {
+	IsRevoked(jti string) (bool, error)
+}
+
+// ExpiresIn - время жизни access-токена (для ответа клиенту)
+func (s *JWTService) ExpiresIn() time.Duration {
+	return s.expireMinutes
+}
+
+// AccessToken - выданный access-токен. jti и срок сохраняются вместе с refresh-токеном,
+// чтобы отозвать токен при logout, повторном использовании refresh или деактивации
+type AccessToken struct {
+	Token     string
+	ID        string
+	ExpiresAt time.Time
+}
+
+func (s *JWTService) CreateToken(userID uint, role string) (*AccessToken, error) {
+	// jti нужен, чтобы токен можно было отозвать при logout
+	jti, err := NewRandomID()
+	if err != nil {
+		return nil, err
 	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.expireMinutes)
+	claims := &JWTClaims{
+		UserID:      userID,
+		Role:        role,
+		Permissions: s.policy.Permissions(role),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+	token, err := s.keys.sign(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessToken{Token: token, ID: jti, ExpiresAt: expiresAt}, nil
 }
 
-func (s *JWTService) CreateToken(userID uint, role string) (string, error) {
+// CreateChallengeToken - короткоживущий токен между вводом пароля и второго фактора
+func (s *JWTService) CreateChallengeToken(userID uint, role, purpose string) (string, error) {
 	claims := &JWTClaims{
-		UserID: userID,
-		Role:   role,
+		UserID:  userID,
+		Role:    role,
+		Purpose: purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.expireMinutes)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ChallengeTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.secretKey))
+	return s.keys.sign(claims)
 }
 
-func (s *JWTService) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.secretKey), nil
-	})
+// ValidateChallengeToken - принимает только challenge-токен с указанным назначением
+func (s *JWTService) ValidateChallengeToken(tokenString, purpose string) (*JWTClaims, error) {
+	claims, err := s.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != purpose {
+		return nil, fmt.Errorf("unexpected token purpose")
+	}
+	return claims, nil
+}
+
+// CreateActionToken - подписанный токен для ссылки в письме.
+// state - то, что изменится после использования (email, хэш пароля): отпечаток
+// состояния зашит в токен, поэтому после использования токен перестает подходить
+func (s *JWTService) CreateActionToken(userID uint, purpose, state string, ttl time.Duration) (string, error) {
+	if s.actionKey == "" {
+		return "", errors.New("ACTION_TOKEN_SECRET is not configured")
+	}
+	claims := &JWTClaims{
+		UserID:      userID,
+		Purpose:     purpose,
+		Fingerprint: s.fingerprint(purpose, state),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return s.keys.sign(claims)
+}
 
+// MatchesState - токен выпущен для текущего состояния пользователя (еще не использован).
+// Подпись, срок и назначение проверяются раньше - через ValidateChallengeToken
+func (s *JWTService) MatchesState(claims *JWTClaims, state string) bool {
+	if s.actionKey == "" {
+		return false
+	}
+	return hmac.Equal([]byte(claims.Fingerprint), []byte(s.fingerprint(claims.Purpose, state)))
+}
+
+func (s *JWTService) fingerprint(purpose, state string) string {
+	mac := hmac.New(sha256.New, []byte(s.actionKey))
+	mac.Write([]byte(purpose + "\x00" + state))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// ValidateToken - проверяет access-токен. Challenge-токены здесь не принимаются
+func (s *JWTService) ValidateToken(tokenString string) (*JWTClaims, error) {
+	claims, err := s.parse(tokenString)
 	if err != nil {
 		return nil, err
 	}
+	if claims.Purpose != "" {
+		return nil, fmt.Errorf("not an access token")
+	}
+
+	// Токены, выпущенные до появления прав, получают права своей роли
+	if claims.Permissions == nil {
+		claims.Permissions = s.policy.Permissions(claims.Role)
+	}
+	return claims, nil
+}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+func (s *JWTService) parse(tokenString string) (*JWTClaims, error) {
+	claims := &JWTClaims{}
+	if err := s.keys.parse(tokenString, claims); err != nil {
+		return nil, err
 	}
-	
-	return nil, fmt.Errorf("invalid token")
-}
\ No newline at end of file
+	return claims, nil
+}
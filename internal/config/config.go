@@ -1,53 +1,306 @@
 package config
 
 import (
-	"log"
+	"ac-ai/internal/logging"
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+// Config - настройки приложения. Теги secret:"true" отмечают значения,
+// которые маскируются в `config print`
 type Config struct {
-	DatabaseURL                string        `mapstructure:"DATABASE_URL"`
-	OpenAIAPIKey               string        `mapstructure:"OPENAI_API_KEY"`
-	JWTSecretKey               string        `mapstructure:"JWT_SECRET_KEY"`
-	JWTAccessTokenExpireMinutes time.Duration `mapstructure:"JWT_ACCESS_TOKEN_EXPIRE_MINUTES"`
-	ServerPort                 string        `mapstructure:"SERVER_PORT"`
+	Env                         string `mapstructure:"APP_ENV"` // dev | test | prod
+	DatabaseURL                 string `mapstructure:"DATABASE_URL" secret:"true"`
+	OpenAIAPIKey                string `mapstructure:"OPENAI_API_KEY" secret:"true"`
+	JWTSecretKey                string `mapstructure:"JWT_SECRET_KEY" secret:"true"`
+	JWTAccessTokenExpireMinutes int    `mapstructure:"JWT_ACCESS_TOKEN_EXPIRE_MINUTES"`
+	JWTRefreshTokenExpireDays   int    `mapstructure:"JWT_REFRESH_TOKEN_EXPIRE_DAYS"`
+	ServerPort                  string `mapstructure:"SERVER_PORT"`
+	EventBusBackend             string `mapstructure:"EVENT_BUS_BACKEND"` // memory | postgres
+	DocumentsDir                string `mapstructure:"DOCUMENTS_DIR"`
+	MaxUploadSizeMB             int64  `mapstructure:"MAX_UPLOAD_SIZE_MB"`
+	InfoRequestExpireHours      int    `mapstructure:"INFO_REQUEST_EXPIRE_HOURS"`
+	// JSON вида {"SUPERVISOR": ["applications:read", "exports:run"]}.
+	// Переопределяет права перечисленных ролей, остальные берутся по умолчанию
+	RolePermissionsJSON string `mapstructure:"ROLE_PERMISSIONS"`
+	// Обязательный TOTP для сотрудников (AGENT, SUPERVISOR, ADMIN)
+	MFARequiredForStaff bool                `mapstructure:"MFA_REQUIRED_FOR_STAFF"`
+	RolePermissions     map[string][]string `mapstructure:"-"`
+
+	// Асимметричная подпись JWT: директория с <kid>.pem и kid активного ключа.
+	// Пусто - подпись HS256 общим секретом JWT_SECRET_KEY
+	JWTKeysDir   string `mapstructure:"JWT_KEYS_DIR"`
+	JWTActiveKID string `mapstructure:"JWT_ACTIVE_KID"`
+	// Сколько после ротации принимаются токены, подписанные прежними ключами
+	JWTKeyGraceHours int `mapstructure:"JWT_KEY_GRACE_HOURS"`
+	// До какого момента (RFC 3339) при заданном JWT_KEYS_DIR принимаются токены
+	// без kid, подписанные JWT_SECRET_KEY. Пусто - не принимаются
+	JWTLegacyAcceptUntilRFC3339 string    `mapstructure:"JWT_LEGACY_ACCEPT_UNTIL"`
+	JWTLegacyAcceptUntil        time.Time `mapstructure:"-"`
+	// Ключ HMAC-отпечатка в ссылках из писем (подтверждение email, сброс пароля).
+	// Пусто - используется JWT_SECRET_KEY
+	ActionTokenSecret string `mapstructure:"ACTION_TOKEN_SECRET" secret:"true"`
+
+	// Защита входа от перебора паролей
+	LoginLockoutStore         string `mapstructure:"LOGIN_LOCKOUT_STORE"` // memory | postgres
+	LoginMaxAccountFailures   int    `mapstructure:"LOGIN_MAX_ACCOUNT_FAILURES"`
+	LoginMaxIPFailures        int    `mapstructure:"LOGIN_MAX_IP_FAILURES"`
+	LoginLockoutBaseSeconds   int    `mapstructure:"LOGIN_LOCKOUT_BASE_SECONDS"`
+	LoginLockoutMaxMinutes    int    `mapstructure:"LOGIN_LOCKOUT_MAX_MINUTES"`
+	LoginFailureWindowMinutes int    `mapstructure:"LOGIN_FAILURE_WINDOW_MINUTES"`
+	// IP и подсети (через запятую) балансировщиков, которым можно верить в
+	// X-Forwarded-For. Пусто - заголовок игнорируется, IP клиента берется из соединения
+	TrustedProxiesCSV string   `mapstructure:"TRUSTED_PROXIES"`
+	TrustedProxies    []string `mapstructure:"-"`
+
+	// Письма: подтверждение email и сброс пароля
+	MailerBackend              string `mapstructure:"MAILER_BACKEND"` // log | file | smtp
+	MailFrom                   string `mapstructure:"MAIL_FROM"`
+	MailOutboxDir              string `mapstructure:"MAIL_OUTBOX_DIR"`
+	SMTPHost                   string `mapstructure:"SMTP_HOST"`
+	SMTPPort                   string `mapstructure:"SMTP_PORT"`
+	SMTPUsername               string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword               string `mapstructure:"SMTP_PASSWORD" secret:"true"`
+	AppBaseURL                 string `mapstructure:"APP_BASE_URL"` // Фронтенд, куда ведут ссылки из писем
+	EmailVerifyExpireHours     int    `mapstructure:"EMAIL_VERIFY_EXPIRE_HOURS"`
+	PasswordResetExpireMinutes int    `mapstructure:"PASSWORD_RESET_EXPIRE_MINUTES"`
+
+	// Лимит запросов в минуту для нового API-ключа партнера, если не задан явно
+	APIKeyDefaultRateLimit int `mapstructure:"API_KEY_DEFAULT_RATE_LIMIT"`
+
+	// Вход сотрудников через корпоративный IdP (OpenID Connect). Пустой issuer - SSO выключен
+	OIDCIssuerURL    string `mapstructure:"OIDC_ISSUER_URL"`
+	OIDCClientID     string `mapstructure:"OIDC_CLIENT_ID"`
+	OIDCClientSecret string `mapstructure:"OIDC_CLIENT_SECRET" secret:"true"`
+	OIDCRedirectURL  string `mapstructure:"OIDC_REDIRECT_URL"` // https://<api>/api/v1/auth/sso/callback
+	OIDCScopes       string `mapstructure:"OIDC_SCOPES"`
+	OIDCGroupsClaim  string `mapstructure:"OIDC_GROUPS_CLAIM"`
+	// JSON вида {"bank-credit-agents": "AGENT", "bank-it-admins": "ADMIN"}
+	OIDCGroupRolesJSON string            `mapstructure:"OIDC_GROUP_ROLES"`
+	OIDCGroupRoles     map[string]string `mapstructure:"-"`
+
+	// Хэширование паролей: bcrypt (с настраиваемым cost) или argon2id
+	PasswordHashAlgorithm string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	BcryptCost            int    `mapstructure:"BCRYPT_COST"`
+	Argon2MemoryKB        int    `mapstructure:"ARGON2_MEMORY_KB"`
+	Argon2Iterations      int    `mapstructure:"ARGON2_ITERATIONS"`
+	Argon2Parallelism     int    `mapstructure:"ARGON2_PARALLELISM"`
+	// Парольная политика
+	PasswordMinLength        int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordBreachedListFile string `mapstructure:"PASSWORD_BREACHED_LIST_FILE"`
+
+	// Курсы валют к тенге: JSON-файл {"USD": "470.25"} или таблица fx_rates
+	FXRatesBackend string `mapstructure:"FX_RATES_BACKEND"` // file | postgres
+	FXRatesFile    string `mapstructure:"FX_RATES_FILE"`
+
+	// Таймауты HTTP-сервера. Запись должна переживать ответ OpenAI, поэтому она длиннее чтения
+	ServerReadTimeoutSeconds     int `mapstructure:"SERVER_READ_TIMEOUT_SECONDS"`
+	ServerWriteTimeoutSeconds    int `mapstructure:"SERVER_WRITE_TIMEOUT_SECONDS"`
+	ServerIdleTimeoutSeconds     int `mapstructure:"SERVER_IDLE_TIMEOUT_SECONDS"`
+	ServerShutdownTimeoutSeconds int `mapstructure:"SERVER_SHUTDOWN_TIMEOUT_SECONDS"` // Сколько ждем завершения запросов после SIGTERM
+	// /readyz дополнительно проверяет доступность OpenAI
+	ReadyzCheckAI bool `mapstructure:"READYZ_CHECK_AI"`
+
+	// Порт для /metrics. Пустой - метрики отдаются на основном порту
+	MetricsPort string `mapstructure:"METRICS_PORT"`
+
+	// Трассировка (OTLP/HTTP). Пустой endpoint - спаны не отправляются
+	OTLPEndpoint          string  `mapstructure:"OTEL_EXPORTER_OTLP_ENDPOINT"`              // например http://otel-collector:4318
+	OTLPHeaders           string  `mapstructure:"OTEL_EXPORTER_OTLP_HEADERS" secret:"true"` // key=value,key2=value2
+	TracingServiceName    string  `mapstructure:"OTEL_SERVICE_NAME"`
+	TracingSampleRatio    float64 `mapstructure:"OTEL_TRACES_SAMPLER_ARG"` // доля корневых трасс, 0..1
+	TracingCapturePrompts bool    `mapstructure:"TRACING_CAPTURE_PROMPTS"` // писать промпты и ответы ИИ в спаны (только для отладки)
+
+	// Логи: уровень, формат и маскирование персональных данных (off | mask | redact)
+	LogLevel         string `mapstructure:"LOG_LEVEL"`  // debug | info | warn | error
+	LogFormat        string `mapstructure:"LOG_FORMAT"` // json | text
+	LogRedactEmails  string `mapstructure:"LOG_REDACT_EMAILS"`
+	LogRedactAmounts string `mapstructure:"LOG_REDACT_AMOUNTS"`
+	LogRedactProfile string `mapstructure:"LOG_REDACT_PROFILE"`
 }
 
+// Профили окружения (APP_ENV)
+const (
+	EnvDev  = "dev"
+	EnvTest = "test"
+	EnvProd = "prod"
+)
+
+var profiles = []string{EnvDev, EnvTest, EnvProd}
+
+// Значения по умолчанию: base.yaml, поверх него - профиль <APP_ENV>.yaml
+//
+//go:embed defaults/*.yaml
+var defaultsFS embed.FS
+
+// fileSuffix - DATABASE_URL_FILE=/run/secrets/db_url: значение читается из файла
+// (секреты Docker/Kubernetes), а не из самой переменной
+const fileSuffix = "_FILE"
+
+// LoadConfig загружает и проверяет конфигурацию
 func LoadConfig() (*Config, error) {
-	// --- ИСПРАВЛЕНИЕ ЗДЕСЬ ---
-	// 1. Сначала говорим Viper явно искать эти ENV-переменные
-	// Это гарантирует, что переменные Render будут найдены
-	viper.BindEnv("DATABASE_URL")
-	viper.BindEnv("OPENAI_API_KEY")
-	viper.BindEnv("JWT_SECRET_KEY")
-	viper.BindEnv("JWT_ACCESS_TOKEN_EXPIRE_MINUTES")
-	viper.BindEnv("SERVER_PORT")
-
-	// 2. Затем (для локальной разработки) пытаемся прочитать .env
-	viper.SetConfigFile(".env")
-	if err := viper.ReadInConfig(); err != nil {
-		log.Printf("Warning: .env file not found, loading *only* from ENV variables.")
-	}
-    // --- КОНЕЦ ИСПРАВЛЕНИЯ ---
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Load собирает конфигурацию без проверки (Validate). Слои, от слабого к сильному:
+//
+//	defaults/base.yaml -> defaults/<APP_ENV>.yaml -> CONFIG_FILE -> .env -> окружение -> *_FILE
+func Load() (*Config, error) {
+	v := viper.New()
+	keys := configKeys()
+	for _, key := range keys {
+		v.BindEnv(key)
+		v.BindEnv(key + fileSuffix)
+	}
+
+	// .env (локальная разработка) читаем первым: в нем может быть APP_ENV
+	dotenv := viper.New()
+	dotenv.SetConfigFile(".env")
+	dotenv.SetConfigType("env")
+	if err := dotenv.ReadInConfig(); err != nil {
+		slog.Warn(".env file not found, loading only from environment variables")
+	}
+	lookup := func(key string) string {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+		return dotenv.GetString(key)
+	}
+
+	env := lookup("APP_ENV")
+	if env == "" {
+		env = EnvDev
+	}
+	if !slices.Contains(profiles, env) {
+		return nil, fmt.Errorf("invalid APP_ENV %q: expected one of %s", env, strings.Join(profiles, ", "))
+	}
+	v.Set("APP_ENV", env)
+
+	v.SetConfigType("yaml")
+	for _, name := range []string{"base", env} {
+		data, err := defaultsFS.ReadFile("defaults/" + name + ".yaml")
+		if err != nil {
+			return nil, err
+		}
+		if err := mergeYAML(v, bytes.NewReader(data), keys); err != nil {
+			return nil, fmt.Errorf("defaults/%s.yaml: %w", name, err)
+		}
+	}
+
+	// Настройки площадки (ConfigMap и т.п.) без секретов
+	if path := lookup("CONFIG_FILE"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("CONFIG_FILE: %w", err)
+		}
+		defer f.Close()
+		if err := mergeYAML(v, f, keys); err != nil {
+			return nil, fmt.Errorf("CONFIG_FILE %s: %w", path, err)
+		}
+	}
+
+	if err := v.MergeConfigMap(dotenv.AllSettings()); err != nil {
+		return nil, fmt.Errorf(".env: %w", err)
+	}
+
+	if err := readSecretFiles(v, keys); err != nil {
+		return nil, err
+	}
 
 	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	if err := v.Unmarshal(&cfg); err != nil {
 		return nil, err
 	}
+	return &cfg, nil
+}
+
+// mergeYAML накладывает YAML-слой. Неизвестный ключ - скорее всего опечатка,
+// которая иначе молча оставила бы значение по умолчанию
+func mergeYAML(v *viper.Viper, r io.Reader, keys []string) error {
+	layer := viper.New()
+	layer.SetConfigType("yaml")
+	if err := layer.ReadConfig(r); err != nil {
+		return err
+	}
+	var unknown []string
+	for _, key := range layer.AllKeys() {
+		if !slices.Contains(keys, strings.ToUpper(key)) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return fmt.Errorf("unknown settings: %s", strings.Join(unknown, ", "))
+	}
+	return v.MergeConfigMap(layer.AllSettings())
+}
 
-	// Установка значения по умолчанию для порта
-	if cfg.ServerPort == "" {
-		cfg.ServerPort = "8080" // Render ожидает порт 8080 или 10000
+// readSecretFiles подставляет значения из файлов *_FILE. Концевой перевод строки
+// (echo > file) отбрасывается
+func readSecretFiles(v *viper.Viper, keys []string) error {
+	var errs []error
+	for _, key := range keys {
+		path := v.GetString(key + fileSuffix)
+		if path == "" {
+			continue
+		}
+		if os.Getenv(key) != "" {
+			errs = append(errs, fmt.Errorf("%s and %s%s are both set", key, key, fileSuffix))
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", key, fileSuffix, err))
+			continue
+		}
+		v.Set(key, strings.TrimRight(string(data), "\r\n"))
 	}
+	return errors.Join(errs...)
+}
 
-	// Установка значения по умолчанию для времени жизни токена
-	if cfg.JWTAccessTokenExpireMinutes == 0 {
-		// Важно: Viper не парсит '60m' из .env, если он не нашел BindEnv
-		// Поэтому мы устанавливаем '60' и умножаем
-		cfg.JWTAccessTokenExpireMinutes = 60 * time.Minute
+// configKeys - имена настроек из тегов mapstructure в порядке объявления полей
+func configKeys() []string {
+	var keys []string
+	t := reflect.TypeFor[Config]()
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" && key != "-" {
+			keys = append(keys, key)
+		}
 	}
+	return keys
+}
 
-	return &cfg, nil
-}
\ No newline at end of file
+// LoggingOptions - настройки slog из LOG_* (значения проверены в LoadConfig)
+func (c *Config) LoggingOptions() (logging.Options, error) {
+	level, err := logging.ParseLevel(c.LogLevel)
+	if err != nil {
+		return logging.Options{}, fmt.Errorf("invalid LOG_LEVEL: %w", err)
+	}
+	format, err := logging.ParseFormat(c.LogFormat)
+	if err != nil {
+		return logging.Options{}, fmt.Errorf("invalid LOG_FORMAT: %w", err)
+	}
+	policy, err := logging.ParsePolicy(c.LogRedactEmails, c.LogRedactAmounts, c.LogRedactProfile)
+	if err != nil {
+		return logging.Options{}, fmt.Errorf("invalid LOG_REDACT_*: %w", err)
+	}
+	return logging.Options{Level: level, Format: format, Policy: policy}, nil
+}
@@ -0,0 +1,74 @@
+// internal/config/print.go
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const mask = "********"
+
+// dsnPasswordRe - password=... в DSN вида "host=db user=app password=secret"
+var dsnPasswordRe = regexp.MustCompile(`(password=)\S+`)
+
+// Print пишет эффективную конфигурацию в YAML (формат слоев defaults/*.yaml,
+// его можно подать обратно через CONFIG_FILE). Секреты маскируются
+func (c *Config) Print(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "# Effective config, APP_ENV=%s. Secrets are masked\n", c.Env); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(c).Elem()
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := f.Tag.Get("mapstructure")
+		if key == "" || key == "-" {
+			continue
+		}
+		value := rv.Field(i).Interface()
+		if s, ok := value.(string); ok && f.Tag.Get("secret") == "true" {
+			value = maskSecret(key, s)
+		}
+		// JSON-литерал - корректный скаляр YAML
+		out, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", strings.ToLower(key), out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maskSecret - у DATABASE_URL скрываем только пароль: хост и имя БД нужны при отладке
+func maskSecret(key, value string) string {
+	if value == "" {
+		return ""
+	}
+	if key == "DATABASE_URL" {
+		if u, err := url.Parse(value); err == nil && u.Scheme != "" {
+			if _, ok := u.User.Password(); ok {
+				u.User = url.UserPassword(u.User.Username(), mask)
+			}
+			// pgx принимает пароль и параметром: postgres://app@db/ac_ai?password=secret
+			params := strings.Split(u.RawQuery, "&")
+			for i, param := range params {
+				if name, _, _ := strings.Cut(param, "="); name == "password" {
+					params[i] = "password=" + mask
+				}
+			}
+			u.RawQuery = strings.Join(params, "&")
+			return strings.Replace(u.String(), url.QueryEscape(mask), mask, 1)
+		}
+		if dsnPasswordRe.MatchString(value) {
+			return dsnPasswordRe.ReplaceAllString(value, "${1}"+mask)
+		}
+	}
+	return mask
+}
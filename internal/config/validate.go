@@ -0,0 +1,283 @@
+// internal/config/validate.go
+package config
+
+import (
+	"ac-ai/internal/models"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minProdSecretLength - HS256-секрет короче 32 байт подбирается перебором
+const minProdSecretLength = 32
+
+// ValidationError - все найденные проблемы конфигурации сразу, чтобы не
+// исправлять их по одной между перезапусками
+type ValidationError struct {
+	Env      string
+	Problems []string // "DATABASE_URL: is required"
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid config (APP_ENV=%s):\n  - %s", e.Env, strings.Join(e.Problems, "\n  - "))
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) add(key, format string, args ...any) {
+	v.problems = append(v.problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(key, "is required")
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.add(key, "%q is not one of %s", value, strings.Join(allowed, ", "))
+	}
+}
+
+func (v *validator) positive(key string, value int64) {
+	if value <= 0 {
+		v.add(key, "must be positive, got %d", value)
+	}
+}
+
+func (v *validator) port(key, value string) {
+	if n, err := strconv.Atoi(value); err != nil || n < 1 || n > 65535 {
+		v.add(key, "%q is not a valid port", value)
+	}
+}
+
+func (v *validator) url(key, value string) *url.URL {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.add(key, "%q is not an absolute http(s) URL", value)
+		return nil
+	}
+	return u
+}
+
+// Validate проверяет конфигурацию и разбирает составные настройки
+// (RolePermissions, OIDCGroupRoles, TrustedProxies). Возвращает *ValidationError
+func (c *Config) Validate() error {
+	v := &validator{}
+	prod := c.Env == EnvProd
+
+	v.oneOf("APP_ENV", c.Env, profiles...)
+
+	// Без этих значений сервер стартует, но падает на первом же запросе
+	v.required("DATABASE_URL", c.DatabaseURL)
+	if c.Env != EnvTest {
+		v.required("OPENAI_API_KEY", c.OpenAIAPIKey)
+	}
+	if c.JWTKeysDir == "" {
+		if c.JWTSecretKey == "" {
+			v.add("JWT_SECRET_KEY", "is required unless JWT_KEYS_DIR is set")
+		}
+	} else {
+		v.required("JWT_ACTIVE_KID", c.JWTActiveKID)
+	}
+	// Абсолютная дата, а не "grace после старта": иначе каждый рестарт или новая
+	// реплика снова открывали бы окно для токенов на старом секрете
+	c.JWTLegacyAcceptUntil = time.Time{}
+	if c.JWTLegacyAcceptUntilRFC3339 != "" {
+		until, err := time.Parse(time.RFC3339, c.JWTLegacyAcceptUntilRFC3339)
+		switch {
+		case err != nil:
+			v.add("JWT_LEGACY_ACCEPT_UNTIL", "expected an RFC 3339 time, e.g. 2026-11-01T00:00:00Z")
+		case c.JWTKeysDir == "":
+			v.add("JWT_LEGACY_ACCEPT_UNTIL", "only applies when JWT_KEYS_DIR is set")
+		case c.JWTSecretKey == "":
+			v.add("JWT_LEGACY_ACCEPT_UNTIL", "requires JWT_SECRET_KEY to verify legacy tokens")
+		default:
+			c.JWTLegacyAcceptUntil = until
+		}
+	}
+	if prod && c.JWTSecretKey != "" && len(c.JWTSecretKey) < minProdSecretLength {
+		v.add("JWT_SECRET_KEY", "must be at least %d characters in prod", minProdSecretLength)
+	}
+	// Без ключа отпечаток в ссылках из писем - обычный хэш, который пересчитает любой
+	if c.ActionTokenSecret == "" && c.JWTSecretKey == "" {
+		v.add("ACTION_TOKEN_SECRET", "is required when JWT_SECRET_KEY is not set")
+	}
+	if prod && c.ActionTokenSecret != "" && len(c.ActionTokenSecret) < minProdSecretLength {
+		v.add("ACTION_TOKEN_SECRET", "must be at least %d characters in prod", minProdSecretLength)
+	}
+
+	v.port("SERVER_PORT", c.ServerPort)
+	if c.MetricsPort != "" {
+		v.port("METRICS_PORT", c.MetricsPort)
+		if c.MetricsPort == c.ServerPort {
+			v.add("METRICS_PORT", "must differ from SERVER_PORT")
+		}
+	}
+
+	v.oneOf("EVENT_BUS_BACKEND", c.EventBusBackend, "memory", "postgres")
+	v.oneOf("LOGIN_LOCKOUT_STORE", c.LoginLockoutStore, "memory", "postgres")
+	v.oneOf("FX_RATES_BACKEND", c.FXRatesBackend, "file", "postgres")
+	if c.FXRatesBackend == "file" {
+		v.required("FX_RATES_FILE", c.FXRatesFile)
+	}
+	v.required("DOCUMENTS_DIR", c.DocumentsDir)
+
+	for key, value := range map[string]int64{
+		"JWT_ACCESS_TOKEN_EXPIRE_MINUTES": int64(c.JWTAccessTokenExpireMinutes),
+		"JWT_REFRESH_TOKEN_EXPIRE_DAYS":   int64(c.JWTRefreshTokenExpireDays),
+		"JWT_KEY_GRACE_HOURS":             int64(c.JWTKeyGraceHours),
+		"MAX_UPLOAD_SIZE_MB":              c.MaxUploadSizeMB,
+		"INFO_REQUEST_EXPIRE_HOURS":       int64(c.InfoRequestExpireHours),
+		"LOGIN_MAX_ACCOUNT_FAILURES":      int64(c.LoginMaxAccountFailures),
+		"LOGIN_MAX_IP_FAILURES":           int64(c.LoginMaxIPFailures),
+		"LOGIN_LOCKOUT_BASE_SECONDS":      int64(c.LoginLockoutBaseSeconds),
+		"LOGIN_LOCKOUT_MAX_MINUTES":       int64(c.LoginLockoutMaxMinutes),
+		"LOGIN_FAILURE_WINDOW_MINUTES":    int64(c.LoginFailureWindowMinutes),
+		"EMAIL_VERIFY_EXPIRE_HOURS":       int64(c.EmailVerifyExpireHours),
+		"PASSWORD_RESET_EXPIRE_MINUTES":   int64(c.PasswordResetExpireMinutes),
+		"API_KEY_DEFAULT_RATE_LIMIT":      int64(c.APIKeyDefaultRateLimit),
+		"PASSWORD_MIN_LENGTH":             int64(c.PasswordMinLength),
+		"SERVER_READ_TIMEOUT_SECONDS":     int64(c.ServerReadTimeoutSeconds),
+		"SERVER_WRITE_TIMEOUT_SECONDS":    int64(c.ServerWriteTimeoutSeconds),
+		"SERVER_IDLE_TIMEOUT_SECONDS":     int64(c.ServerIdleTimeoutSeconds),
+		"SERVER_SHUTDOWN_TIMEOUT_SECONDS": int64(c.ServerShutdownTimeoutSeconds),
+	} {
+		v.positive(key, value)
+	}
+	if c.LoginLockoutBaseSeconds > c.LoginLockoutMaxMinutes*60 {
+		v.add("LOGIN_LOCKOUT_BASE_SECONDS", "must not exceed LOGIN_LOCKOUT_MAX_MINUTES")
+	}
+	c.TrustedProxies = nil
+	for _, proxy := range strings.Split(c.TrustedProxiesCSV, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		// Тот же разбор, что в gin.Engine.SetTrustedProxies
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			v.add("TRUSTED_PROXIES", "%q is not an IP address or CIDR", proxy)
+			continue
+		}
+		c.TrustedProxies = append(c.TrustedProxies, proxy)
+	}
+
+	// Диапазоны совпадают с проверками auth.ConfigurePasswords
+	v.oneOf("PASSWORD_HASH_ALGORITHM", c.PasswordHashAlgorithm, "bcrypt", "argon2id")
+	switch c.PasswordHashAlgorithm {
+	case "bcrypt":
+		if c.BcryptCost < 4 || c.BcryptCost > 31 {
+			v.add("BCRYPT_COST", "must be between 4 and 31, got %d", c.BcryptCost)
+		}
+	case "argon2id":
+		v.positive("ARGON2_ITERATIONS", int64(c.Argon2Iterations))
+		if c.Argon2Parallelism < 1 || c.Argon2Parallelism > 255 {
+			v.add("ARGON2_PARALLELISM", "must be between 1 and 255, got %d", c.Argon2Parallelism)
+		}
+		if c.Argon2MemoryKB < 8*c.Argon2Parallelism {
+			v.add("ARGON2_MEMORY_KB", "must be at least 8 * ARGON2_PARALLELISM")
+		}
+	}
+
+	v.oneOf("MAILER_BACKEND", c.MailerBackend, "log", "file", "smtp")
+	switch c.MailerBackend {
+	case "smtp":
+		v.required("SMTP_HOST", c.SMTPHost)
+		v.port("SMTP_PORT", c.SMTPPort)
+	case "file":
+		v.required("MAIL_OUTBOX_DIR", c.MailOutboxDir)
+	case "log":
+		if prod {
+			v.add("MAILER_BACKEND", "log only writes emails to the log; use smtp in prod")
+		}
+	}
+	if _, err := mail.ParseAddress(c.MailFrom); err != nil {
+		v.add("MAIL_FROM", "%q is not a valid email address", c.MailFrom)
+	}
+	if u := v.url("APP_BASE_URL", c.AppBaseURL); u != nil && prod && u.Scheme != "https" {
+		v.add("APP_BASE_URL", "must use https in prod")
+	}
+
+	if c.OIDCIssuerURL != "" {
+		v.url("OIDC_ISSUER_URL", c.OIDCIssuerURL)
+		v.required("OIDC_CLIENT_ID", c.OIDCClientID)
+		v.url("OIDC_REDIRECT_URL", c.OIDCRedirectURL)
+	}
+	c.OIDCGroupRoles = nil
+	if c.OIDCGroupRolesJSON != "" {
+		if err := json.Unmarshal([]byte(c.OIDCGroupRolesJSON), &c.OIDCGroupRoles); err != nil {
+			v.add("OIDC_GROUP_ROLES", "invalid JSON: %v", err)
+		}
+		for group, role := range c.OIDCGroupRoles {
+			if !slices.Contains(models.StaffRoles, role) {
+				v.add("OIDC_GROUP_ROLES", "group %q mapped to unknown staff role %q", group, role)
+			}
+		}
+	}
+	c.RolePermissions = nil
+	if c.RolePermissionsJSON != "" {
+		if err := json.Unmarshal([]byte(c.RolePermissionsJSON), &c.RolePermissions); err != nil {
+			v.add("ROLE_PERMISSIONS", "invalid JSON: %v", err)
+		}
+		// Опечатка в праве молча отняла бы его у роли, опечатка в роли - не переопределила бы ничего
+		for role, perms := range c.RolePermissions {
+			if role != models.RoleClient && !slices.Contains(models.StaffRoles, role) {
+				v.add("ROLE_PERMISSIONS", "unknown role %q", role)
+			}
+			for _, perm := range perms {
+				if !slices.Contains(models.AllPermissions, perm) {
+					v.add("ROLE_PERMISSIONS", "unknown permission %q for role %q", perm, role)
+				}
+			}
+		}
+	}
+
+	if c.OTLPEndpoint != "" {
+		v.url("OTEL_EXPORTER_OTLP_ENDPOINT", c.OTLPEndpoint)
+	}
+	for _, pair := range strings.Split(c.OTLPHeaders, ",") {
+		if k, _, ok := strings.Cut(pair, "="); strings.TrimSpace(pair) != "" && (!ok || strings.TrimSpace(k) == "") {
+			// Значение заголовка - секрет, в ошибку его не пишем
+			v.add("OTEL_EXPORTER_OTLP_HEADERS", "expected key=value pairs separated by commas")
+			break
+		}
+	}
+	v.required("OTEL_SERVICE_NAME", c.TracingServiceName)
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		v.add("OTEL_TRACES_SAMPLER_ARG", "%v is not in [0, 1]", c.TracingSampleRatio)
+	}
+	if prod && c.TracingCapturePrompts {
+		v.add("TRACING_CAPTURE_PROMPTS", "prompts contain client financial data; not allowed in prod")
+	}
+
+	if _, err := c.LoggingOptions(); err != nil {
+		key, msg, _ := strings.Cut(err.Error(), ": ")
+		v.add(strings.TrimPrefix(key, "invalid "), "%s", msg)
+	}
+	if prod {
+		for key, mode := range map[string]string{
+			"LOG_REDACT_EMAILS":  c.LogRedactEmails,
+			"LOG_REDACT_AMOUNTS": c.LogRedactAmounts,
+			"LOG_REDACT_PROFILE": c.LogRedactProfile,
+		} {
+			if strings.EqualFold(mode, "off") {
+				v.add(key, "redaction cannot be off in prod")
+			}
+		}
+	}
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	slices.Sort(v.problems)
+	return &ValidationError{Env: c.Env, Problems: v.problems}
+}
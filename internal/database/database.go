@@ -2,31 +2,25 @@ package database
 
 import (
 	"ac-ai/internal/config"
-	"ac-ai/internal/models"
-	"log"
+	"ac-ai/internal/tracing"
+	"log/slog"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// InitDB только подключается к БД. Схема ведётся версионированными
+// миграциями (см. migrate.go и команду server migrate up).
 func InitDB(cfg *config.Config) (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
-
-	log.Println("Database connection established.")
-
-	// Автоматическая миграция
-	err = db.AutoMigrate(
-		&models.User{},
-		&models.FinancialProfile{},
-		&models.ScoringApplication{},
-	)
-	if err != nil {
+	// Спаны запросов - только для вызовов с контекстом трассы (db.WithContext)
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
 		return nil, err
 	}
 
-	log.Println("Database migrated.")
+	slog.Info("Database connection established")
 	return db, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS oidc_login_states;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS audit_events;
+DROP TABLE IF EXISTS login_throttles;
+DROP TABLE IF EXISTS revoked_access_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS documents;
+DROP TABLE IF EXISTS info_requests;
+DROP TABLE IF EXISTS scoring_applications;
+DROP TABLE IF EXISTS financial_profiles;
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS staff_invites;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,191 @@
+-- Базовая схема. IF NOT EXISTS - чтобы базы, созданные раньше через AutoMigrate,
+-- приняли эту миграцию без изменений
+
+CREATE TABLE IF NOT EXISTS users (
+    id                bigserial PRIMARY KEY,
+    created_at        timestamptz,
+    updated_at        timestamptz,
+    deleted_at        timestamptz,
+    email             varchar(100) NOT NULL,
+    password_hash     text NOT NULL,
+    role              varchar(10) NOT NULL,
+    status            varchar(20) NOT NULL DEFAULT 'ACTIVE',
+    email_verified_at timestamptz,
+    oidc_subject      varchar(255),
+    totp_secret       varchar(64),
+    totp_enabled_at   timestamptz,
+    totp_last_step    bigint
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_oidc_subject ON users (oidc_subject);
+
+CREATE TABLE IF NOT EXISTS staff_invites (
+    id            bigserial PRIMARY KEY,
+    created_at    timestamptz,
+    user_id       bigint NOT NULL,
+    invited_by_id bigint NOT NULL,
+    token_hash    varchar(64) NOT NULL,
+    expires_at    timestamptz NOT NULL,
+    used_at       timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_staff_invites_user_id ON staff_invites (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_staff_invites_token_hash ON staff_invites (token_hash);
+
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    user_id    bigint NOT NULL,
+    code_hash  varchar(64) NOT NULL,
+    used_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
+
+CREATE TABLE IF NOT EXISTS financial_profiles (
+    id                   bigserial PRIMARY KEY,
+    created_at           timestamptz,
+    updated_at           timestamptz,
+    deleted_at           timestamptz,
+    user_id              bigint NOT NULL,
+    income               decimal NOT NULL,
+    monthly_payments     decimal NOT NULL,
+    credit_history       varchar(20) NOT NULL,
+    job_experience_years decimal NOT NULL,
+    age                  bigint NOT NULL,
+    income_proof         varchar(20) NOT NULL,
+    CONSTRAINT fk_users_financial_profile FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_financial_profiles_deleted_at ON financial_profiles (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_financial_profiles_user_id ON financial_profiles (user_id);
+
+CREATE TABLE IF NOT EXISTS scoring_applications (
+    id                     bigserial PRIMARY KEY,
+    created_at             timestamptz,
+    updated_at             timestamptz,
+    deleted_at             timestamptz,
+    user_id                bigint NOT NULL,
+    requested_amount       decimal NOT NULL,
+    final_decision         varchar(20) NOT NULL,
+    cold_score             bigint,
+    recommended_max_amount decimal,
+    ai_response            text,
+    internal_reasons       text,
+    agent_status           varchar(20) DEFAULT 'PENDING',
+    agent_notes            text,
+    reviewed_by_id         bigint,
+    reviewed_at            timestamptz,
+    CONSTRAINT fk_users_scoring_applications FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_scoring_applications_deleted_at ON scoring_applications (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_scoring_applications_reviewed_by_id ON scoring_applications (reviewed_by_id);
+
+CREATE TABLE IF NOT EXISTS info_requests (
+    id             bigserial PRIMARY KEY,
+    created_at     timestamptz,
+    updated_at     timestamptz,
+    deleted_at     timestamptz,
+    application_id bigint NOT NULL,
+    agent_id       bigint NOT NULL,
+    kind           varchar(20) NOT NULL,
+    message        text NOT NULL,
+    status         varchar(20) NOT NULL,
+    expires_at     timestamptz NOT NULL,
+    response_text  text,
+    responded_at   timestamptz,
+    CONSTRAINT fk_scoring_applications_info_requests FOREIGN KEY (application_id) REFERENCES scoring_applications (id)
+);
+CREATE INDEX IF NOT EXISTS idx_info_requests_deleted_at ON info_requests (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_info_requests_application_id ON info_requests (application_id);
+CREATE INDEX IF NOT EXISTS idx_info_requests_status ON info_requests (status);
+CREATE INDEX IF NOT EXISTS idx_info_requests_expires_at ON info_requests (expires_at);
+
+CREATE TABLE IF NOT EXISTS documents (
+    id              bigserial PRIMARY KEY,
+    created_at      timestamptz,
+    updated_at      timestamptz,
+    deleted_at      timestamptz,
+    user_id         bigint NOT NULL,
+    kind            varchar(30) NOT NULL,
+    filename        varchar(255) NOT NULL,
+    content_type    varchar(100) NOT NULL,
+    size            bigint NOT NULL,
+    storage_key     varchar(255),
+    info_request_id bigint,
+    status          varchar(20) NOT NULL,
+    review_notes    text,
+    reviewed_by_id  bigint,
+    reviewed_at     timestamptz,
+    CONSTRAINT fk_documents_user FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT fk_info_requests_attachments FOREIGN KEY (info_request_id) REFERENCES info_requests (id)
+);
+CREATE INDEX IF NOT EXISTS idx_documents_deleted_at ON documents (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_documents_user_id ON documents (user_id);
+CREATE INDEX IF NOT EXISTS idx_documents_info_request_id ON documents (info_request_id);
+CREATE INDEX IF NOT EXISTS idx_documents_status ON documents (status);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    user_id    bigint NOT NULL,
+    family_id  varchar(32) NOT NULL,
+    token_hash varchar(64) NOT NULL,
+    expires_at timestamptz NOT NULL,
+    used_at    timestamptz,
+    revoked_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS revoked_access_tokens (
+    jti        varchar(32) PRIMARY KEY,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_access_tokens_expires_at ON revoked_access_tokens (expires_at);
+
+CREATE TABLE IF NOT EXISTS login_throttles (
+    key             varchar(320) PRIMARY KEY,
+    failures        bigint NOT NULL,
+    last_failure_at timestamptz NOT NULL,
+    locked_until    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_login_throttles_last_failure_at ON login_throttles (last_failure_at);
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id         bigserial PRIMARY KEY,
+    created_at timestamptz,
+    action     varchar(64) NOT NULL,
+    actor_id   bigint,
+    subject    varchar(320),
+    ip         varchar(64),
+    details    text
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_events_subject ON audit_events (subject);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id            bigserial PRIMARY KEY,
+    created_at    timestamptz,
+    name          varchar(100) NOT NULL,
+    prefix        varchar(16) NOT NULL,
+    key_hash      varchar(64) NOT NULL,
+    scopes        text NOT NULL,
+    rate_limit    bigint NOT NULL,
+    created_by_id bigint NOT NULL,
+    last_used_at  timestamptz,
+    revoked_at    timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys (prefix);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys (key_hash);
+
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state_hash    varchar(64) PRIMARY KEY,
+    nonce         varchar(64) NOT NULL,
+    code_verifier varchar(128) NOT NULL,
+    expires_at    timestamptz NOT NULL,
+    created_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_oidc_login_states_expires_at ON oidc_login_states (expires_at);
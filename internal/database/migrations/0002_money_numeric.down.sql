@@ -0,0 +1,7 @@
+ALTER TABLE scoring_applications
+    ALTER COLUMN requested_amount TYPE decimal,
+    ALTER COLUMN recommended_max_amount TYPE decimal;
+
+ALTER TABLE financial_profiles
+    ALTER COLUMN income TYPE decimal,
+    ALTER COLUMN monthly_payments TYPE decimal;
@@ -0,0 +1,8 @@
+-- Денежные суммы: произвольный decimal -> numeric(20,2), округление до тиына
+ALTER TABLE financial_profiles
+    ALTER COLUMN income TYPE numeric(20,2) USING round(income::numeric, 2),
+    ALTER COLUMN monthly_payments TYPE numeric(20,2) USING round(monthly_payments::numeric, 2);
+
+ALTER TABLE scoring_applications
+    ALTER COLUMN requested_amount TYPE numeric(20,2) USING round(requested_amount::numeric, 2),
+    ALTER COLUMN recommended_max_amount TYPE numeric(20,2) USING round(recommended_max_amount::numeric, 2);
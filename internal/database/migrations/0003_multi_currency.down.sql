@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS fx_rates;
+
+ALTER TABLE scoring_applications
+    DROP COLUMN IF EXISTS requested_amount_base,
+    DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE financial_profiles
+    DROP COLUMN IF EXISTS currency;
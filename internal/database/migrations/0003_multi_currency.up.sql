@@ -0,0 +1,19 @@
+-- Валюта дохода клиента и валюта кредита; до этой миграции все суммы были в тенге
+ALTER TABLE financial_profiles
+    ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT 'KZT';
+
+ALTER TABLE scoring_applications
+    ADD COLUMN IF NOT EXISTS currency varchar(3) NOT NULL DEFAULT 'KZT',
+    ADD COLUMN IF NOT EXISTS requested_amount_base numeric(20,2);
+
+UPDATE scoring_applications SET requested_amount_base = requested_amount WHERE requested_amount_base IS NULL;
+
+ALTER TABLE scoring_applications
+    ALTER COLUMN requested_amount_base SET NOT NULL;
+
+-- Курсы к базовой валюте (KZT) для FX_RATES_BACKEND=postgres
+CREATE TABLE IF NOT EXISTS fx_rates (
+    currency     varchar(3) PRIMARY KEY,
+    rate_to_base numeric(20,6) NOT NULL CHECK (rate_to_base > 0),
+    updated_at   timestamptz
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_refresh_tokens_expires_at;
+
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS access_expires_at,
+    DROP COLUMN IF EXISTS access_jti;
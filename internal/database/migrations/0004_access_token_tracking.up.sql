@@ -0,0 +1,9 @@
+-- Access-токен, выданный вместе с refresh-токеном: при отзыве семьи
+-- (повторное использование, logout) или деактивации пользователя
+-- его jti переносится в revoked_access_tokens
+ALTER TABLE refresh_tokens
+    ADD COLUMN IF NOT EXISTS access_jti varchar(32),
+    ADD COLUMN IF NOT EXISTS access_expires_at timestamptz;
+
+-- Для периодической очистки истекших refresh-токенов
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens (expires_at);
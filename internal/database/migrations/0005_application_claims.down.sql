@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_scoring_applications_claimed_by_id;
+
+ALTER TABLE scoring_applications
+    DROP COLUMN IF EXISTS claimed_at,
+    DROP COLUMN IF EXISTS claimed_by_id;
@@ -0,0 +1,7 @@
+-- Агент берет заявку из очереди в работу, чтобы двое не рассматривали ее одновременно.
+-- Закрепление истекает (ApplicationClaimTTL), брошенную заявку может взять другой
+ALTER TABLE scoring_applications
+    ADD COLUMN IF NOT EXISTS claimed_by_id bigint,
+    ADD COLUMN IF NOT EXISTS claimed_at timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_scoring_applications_claimed_by_id ON scoring_applications (claimed_by_id);
@@ -0,0 +1,90 @@
+// internal/events/bus.go
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Типы событий очереди заявок
+const (
+	ApplicationCreated = "application.created"
+	ApplicationDecided = "application.decided"
+	// Агент взял заявку в работу / вернул ее в общую очередь
+	ApplicationClaimed  = "application.claimed"
+	ApplicationReleased = "application.released"
+	// Агент запросил информацию - заявка ушла из очереди
+	ApplicationInfoRequested = "application.info_requested"
+	// Клиент ответил - заявка вернулась в очередь
+	ApplicationInfoProvided = "application.info_provided"
+)
+
+// Размер буфера подписчика. Медленный подписчик теряет события,
+// но никогда не блокирует того, кто публикует
+const subscriberBuffer = 64
+
+// Event - событие по заявке, которое получают агенты
+type Event struct {
+	Type          string    `json:"type"`
+	ApplicationID uint      `json:"application_id"`
+	FinalDecision string    `json:"final_decision"`
+	AgentStatus   string    `json:"agent_status"`
+	AgentID       *uint     `json:"agent_id,omitempty"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// Publisher - то, что нужно хэндлерам и репозиториям
+type Publisher interface {
+	Publish(event Event)
+}
+
+// Bus - шина событий. Subscribe возвращает канал и функцию отписки
+type Bus interface {
+	Publisher
+	Subscribe() (<-chan Event, func())
+}
+
+// MemoryBus - шина внутри одного процесса
+type MemoryBus struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{subscribers: make(map[chan Event]struct{})}
+}
+
+func (b *MemoryBus) Publish(event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Подписчик не успевает - пропускаем событие
+		}
+	}
+}
+
+func (b *MemoryBus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
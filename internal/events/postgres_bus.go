@@ -0,0 +1,99 @@
+// internal/events/postgres_bus.go
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// Канал Postgres, через который инстансы обмениваются событиями
+const notifyChannel = "application_events"
+
+// Пауза перед переподключением LISTEN-соединения
+const listenRetryDelay = 3 * time.Second
+
+// PostgresBus - шина для нескольких инстансов через LISTEN/NOTIFY.
+// Publish делает NOTIFY, а каждый инстанс (включая отправителя) получает
+// событие через LISTEN и раздает его своим локальным подписчикам
+type PostgresBus struct {
+	local *MemoryBus
+	db    *gorm.DB
+	dsn   string
+}
+
+// NewPostgresBus - запускает фоновое LISTEN-соединение, которое живет до отмены ctx
+func NewPostgresBus(ctx context.Context, db *gorm.DB, dsn string) *PostgresBus {
+	b := &PostgresBus{
+		local: NewMemoryBus(),
+		db:    db,
+		dsn:   dsn,
+	}
+	go b.listen(ctx)
+	return b
+}
+
+func (b *PostgresBus) Publish(event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	payload, err := json.Marshal(event)
+	if err == nil {
+		err = b.db.Exec("SELECT pg_notify(?, ?)", notifyChannel, string(payload)).Error
+	}
+	if err != nil {
+		// Другие инстансы событие не увидят, но локальные агенты - должны
+		slog.Error("Failed to NOTIFY event", slog.String("type", string(event.Type)), slog.Any("error", err))
+		b.local.Publish(event)
+	}
+}
+
+func (b *PostgresBus) Subscribe() (<-chan Event, func()) {
+	return b.local.Subscribe()
+}
+
+// listen - держит отдельное соединение (пул GORM для LISTEN не подходит)
+func (b *PostgresBus) listen(ctx context.Context) {
+	for {
+		if err := b.listenOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "LISTEN failed, reconnecting", slog.String("channel", notifyChannel), slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+func (b *PostgresBus) listenOnce(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+notifyChannel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			slog.WarnContext(ctx, "Skipping malformed event payload", slog.Any("error", err))
+			continue
+		}
+		b.local.Publish(event)
+	}
+}
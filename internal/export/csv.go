@@ -0,0 +1,69 @@
+// internal/export/csv.go
+package export
+
+import (
+	"ac-ai/internal/money"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type csvWriter struct {
+	out     io.Writer
+	w       *csv.Writer
+	started bool
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	return &csvWriter{out: w, w: csv.NewWriter(w)}, nil
+}
+
+// start - BOM нужен, чтобы Excel правильно открыл кириллицу. Пишется вместе
+// с первой строкой: до нее в ответ ничего не уходит
+func (cw *csvWriter) start() error {
+	if cw.started {
+		return nil
+	}
+	cw.started = true
+	_, err := io.WriteString(cw.out, "\uFEFF")
+	return err
+}
+
+func (cw *csvWriter) WriteRow(cells []any) error {
+	if err := cw.start(); err != nil {
+		return err
+	}
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		record[i] = formatCell(cell)
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Close() error {
+	if err := cw.start(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// formatCell - текстовое представление ячейки (общее для CSV и строк XLSX)
+func formatCell(cell any) string {
+	switch v := cell.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case money.Amount:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
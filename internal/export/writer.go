@@ -0,0 +1,42 @@
+// internal/export/writer.go
+package export
+
+import (
+	"fmt"
+	"io"
+)
+
+// Поддерживаемые форматы выгрузки
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// RowWriter - построчная запись таблицы в выходной поток.
+// Реализации не держат строки в памяти, а сразу пишут их в io.Writer
+type RowWriter interface {
+	WriteRow(cells []any) error
+	// Close дописывает "хвост" файла (для XLSX - закрывает архив)
+	Close() error
+}
+
+// NewRowWriter - создает writer под нужный формат. До первой WriteRow
+// в w ничего не пишется, так что ошибку формата еще можно вернуть обычным ответом
+func NewRowWriter(format string, w io.Writer) (RowWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// ContentType - MIME-тип для заголовка ответа
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
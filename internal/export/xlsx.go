@@ -0,0 +1,118 @@
+// internal/export/xlsx.go
+package export
+
+import (
+	"ac-ai/internal/money"
+	"archive/zip"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// Минимальный набор частей OOXML-пакета, без которых Excel не откроет файл.
+// Лист пишется напрямую в zip-поток, поэтому весь файл в памяти не собирается
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+</Relationships>`},
+}
+
+const (
+	xlsxSheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetFooter = `</sheetData></worksheet>`
+)
+
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+	buf   strings.Builder
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	return &xlsxWriter{zw: zip.NewWriter(w)}, nil
+}
+
+// start - служебные части пакета пишутся вместе с первой строкой,
+// до нее в ответ ничего не уходит
+func (xw *xlsxWriter) start() error {
+	if xw.sheet != nil {
+		return nil
+	}
+	for _, part := range xlsxStaticParts {
+		f, err := xw.zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+
+	// Лист создаем последним: после Create предыдущие части уже закрыты
+	sheet, err := xw.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(sheet, xlsxSheetHeader); err != nil {
+		return err
+	}
+	xw.sheet = sheet
+	return nil
+}
+
+func (xw *xlsxWriter) WriteRow(cells []any) error {
+	if err := xw.start(); err != nil {
+		return err
+	}
+	xw.buf.Reset()
+	xw.buf.WriteString("<row>")
+	for _, cell := range cells {
+		switch cell.(type) {
+		case int, int64, uint, float64, money.Amount:
+			// Числа пишем как числа, чтобы в Excel работали формулы и сортировка
+			xw.buf.WriteString("<c><v>")
+			xw.buf.WriteString(formatCell(cell))
+			xw.buf.WriteString("</v></c>")
+		default:
+			xw.buf.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(&xw.buf, []byte(formatCell(cell))); err != nil {
+				return err
+			}
+			xw.buf.WriteString("</t></is></c>")
+		}
+	}
+	xw.buf.WriteString("</row>")
+
+	_, err := io.WriteString(xw.sheet, xw.buf.String())
+	return err
+}
+
+func (xw *xlsxWriter) Close() error {
+	if err := xw.start(); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(xw.sheet, xlsxSheetFooter); err != nil {
+		return err
+	}
+	return xw.zw.Close()
+}
@@ -0,0 +1,164 @@
+// internal/lockout/guard.go
+package lockout
+
+import (
+	"strings"
+	"time"
+)
+
+// State - счетчик неудачных попыток по одному ключу (аккаунт или IP)
+type State struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// Store - хранилище счетчиков. Реализации: в памяти (один инстанс) и Postgres (несколько реплик)
+type Store interface {
+	// Get - текущее состояние ключа (нулевое, если записей нет)
+	Get(key string) (State, error)
+	// RegisterFailure - атомарно увеличивает счетчик. Если последняя активность
+	// по ключу была раньше resetBefore, счетчик начинается заново
+	RegisterFailure(key string, now, resetBefore time.Time) (State, error)
+	// Lock - блокирует ключ до указанного момента
+	Lock(key string, until time.Time) error
+	// Reset - удаляет счетчик (успешный вход или разблокировка администратором)
+	Reset(key string) error
+}
+
+// Policy - пороги и длительность блокировок
+type Policy struct {
+	MaxAccountFailures int           // Неудач подряд до блокировки аккаунта
+	MaxIPFailures      int           // Неудач с одного IP (по любым аккаунтам) до блокировки IP
+	BaseLockout        time.Duration // Первая блокировка, дальше - в 2 раза дольше
+	MaxLockout         time.Duration // Верхняя граница блокировки
+	Window             time.Duration // Через сколько без неудач счетчик обнуляется
+}
+
+// Lockout - блокировка, начавшаяся после очередной неудачи (для аудита)
+type Lockout struct {
+	Key      string
+	Failures int
+	Until    time.Time
+}
+
+// Guard - защита входа от перебора паролей
+type Guard struct {
+	store  Store
+	policy Policy
+}
+
+func NewGuard(store Store, policy Policy) *Guard {
+	return &Guard{store: store, policy: policy}
+}
+
+// AccountKey - ключ счетчика аккаунта (email без учета регистра)
+func AccountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// IPKey - ключ счетчика IP-адреса
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check - сколько еще ждать до следующей попытки (0 - можно пробовать).
+// Вызывается ДО проверки пароля, чтобы заблокированный перебор не тратил CPU на bcrypt.
+//
+// Check и RegisterFailure не атомарны: параллельные попытки, прошедшие Check до
+// блокировки, проверят свои пароли, поэтому перебор получает MaxAccountFailures плюс
+// число одновременных запросов. SELECT ... FOR UPDATE этого не исправит - блокировка
+// строки не переживает проверку пароля. Лишние попытки ограничивает счетчик IP,
+// а каждая из них продлевает backoff
+func (g *Guard) Check(email, ip string) (time.Duration, error) {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range []string{AccountKey(email), IPKey(ip)} {
+		state, err := g.store.Get(key)
+		if err != nil {
+			return 0, err
+		}
+		if d := state.LockedUntil.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	return wait, nil
+}
+
+// RegisterFailure - учитывает неудачу по аккаунту и IP.
+// Возвращает блокировки, которые начались прямо сейчас
+func (g *Guard) RegisterFailure(email, ip string) ([]Lockout, error) {
+	var started []Lockout
+
+	limits := []struct {
+		key string
+		max int
+	}{
+		{AccountKey(email), g.policy.MaxAccountFailures},
+		{IPKey(ip), g.policy.MaxIPFailures},
+	}
+	for _, limit := range limits {
+		lockout, err := g.registerFailure(limit.key, limit.max)
+		if err != nil {
+			return started, err
+		}
+		if lockout != nil {
+			started = append(started, *lockout)
+		}
+	}
+	return started, nil
+}
+
+func (g *Guard) registerFailure(key string, max int) (*Lockout, error) {
+	now := time.Now()
+	state, err := g.store.RegisterFailure(key, now, now.Add(-g.policy.Window))
+	if err != nil {
+		return nil, err
+	}
+	if max <= 0 || state.Failures < max {
+		return nil, nil
+	}
+
+	until := now.Add(g.lockoutDuration(state.Failures - max))
+	if err := g.store.Lock(key, until); err != nil {
+		return nil, err
+	}
+	return &Lockout{Key: key, Failures: state.Failures, Until: until}, nil
+}
+
+// lockoutDuration - экспоненциальный рост: base, 2*base, 4*base ... до MaxLockout
+func (g *Guard) lockoutDuration(extraFailures int) time.Duration {
+	d := g.policy.BaseLockout
+	for i := 0; i < extraFailures && d < g.policy.MaxLockout; i++ {
+		d *= 2
+	}
+	if g.policy.MaxLockout > 0 && d > g.policy.MaxLockout {
+		d = g.policy.MaxLockout
+	}
+	return d
+}
+
+// RegisterSuccess - сбрасывает счетчик аккаунта. Счетчик IP не трогаем:
+// иначе перебор чужих аккаунтов можно "обнулять" входом в свой
+func (g *Guard) RegisterSuccess(email string) error {
+	return g.store.Reset(AccountKey(email))
+}
+
+// UnlockAccount - ручная разблокировка аккаунта администратором
+func (g *Guard) UnlockAccount(email string) error {
+	return g.store.Reset(AccountKey(email))
+}
+
+// UnlockIP - ручная разблокировка IP-адреса администратором
+func (g *Guard) UnlockIP(ip string) error {
+	return g.store.Reset(IPKey(ip))
+}
+
+// lastActivity - момент, от которого отсчитывается окно обнуления счетчика.
+// Пока идет блокировка, счетчик не должен обнулиться, иначе backoff не растет
+func lastActivity(state State) time.Time {
+	if state.LockedUntil.After(state.LastFailureAt) {
+		return state.LockedUntil
+	}
+	return state.LastFailureAt
+}
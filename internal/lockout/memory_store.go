@@ -0,0 +1,71 @@
+// internal/lockout/memory_store.go
+package lockout
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore - счетчики в памяти процесса. Подходит для одного инстанса
+type MemoryStore struct {
+	mu        sync.Mutex
+	states    map[string]State
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: make(map[string]State)}
+}
+
+func (s *MemoryStore) Get(key string) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[key], nil
+}
+
+func (s *MemoryStore) RegisterFailure(key string, now, resetBefore time.Time) (State, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now, resetBefore)
+
+	state := s.states[key]
+	if lastActivity(state).Before(resetBefore) {
+		state = State{}
+	}
+	state.Failures++
+	state.LastFailureAt = now
+	s.states[key] = state
+	return state, nil
+}
+
+func (s *MemoryStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.states[key]
+	state.LockedUntil = until
+	s.states[key] = state
+	return nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, key)
+	return nil
+}
+
+// sweep - удаляет устаревшие счетчики, чтобы перебор с множества IP не раздувал память.
+// Выполняется не чаще одного раза за окно
+func (s *MemoryStore) sweep(now, resetBefore time.Time) {
+	if now.Sub(s.lastSweep) < now.Sub(resetBefore) {
+		return
+	}
+	s.lastSweep = now
+	for key, state := range s.states {
+		if lastActivity(state).Before(resetBefore) {
+			delete(s.states, key)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// internal/lockout/postgres_store.go
+package lockout
+
+import (
+	"ac-ai/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresStore - общие счетчики для всех реплик (таблица login_throttles)
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Get(key string) (State, error) {
+	var row models.LoginThrottle
+	err := s.db.Where("key = ?", key).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+	return toState(row), nil
+}
+
+// RegisterFailure - один UPSERT, чтобы параллельные попытки не теряли инкременты.
+// Гонку с Guard.Check это не закрывает (см. комментарий к Check)
+func (s *PostgresStore) RegisterFailure(key string, now, resetBefore time.Time) (State, error) {
+	var row models.LoginThrottle
+	err := s.db.Raw(`
+		INSERT INTO login_throttles (key, failures, last_failure_at, locked_until)
+		VALUES (?, 1, ?, NULL)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN GREATEST(login_throttles.last_failure_at, COALESCE(login_throttles.locked_until, login_throttles.last_failure_at)) < ?
+				THEN 1
+				ELSE login_throttles.failures + 1
+			END,
+			locked_until = CASE
+				WHEN GREATEST(login_throttles.last_failure_at, COALESCE(login_throttles.locked_until, login_throttles.last_failure_at)) < ?
+				THEN NULL
+				ELSE login_throttles.locked_until
+			END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING key, failures, last_failure_at, locked_until`,
+		key, now, resetBefore, resetBefore,
+	).Scan(&row).Error
+	if err != nil {
+		return State{}, err
+	}
+	return toState(row), nil
+}
+
+func (s *PostgresStore) Lock(key string, until time.Time) error {
+	return s.db.Model(&models.LoginThrottle{}).
+		Where("key = ?", key).
+		Update("locked_until", until).Error
+}
+
+func (s *PostgresStore) Reset(key string) error {
+	return s.db.Where("key = ?", key).Delete(&models.LoginThrottle{}).Error
+}
+
+// PurgeStale - удаляет счетчики без активности с resetBefore: такие счетчики
+// RegisterFailure все равно начал бы заново. Вызывается периодической очисткой
+func (s *PostgresStore) PurgeStale(resetBefore time.Time) (int64, error) {
+	result := s.db.
+		Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", resetBefore, resetBefore).
+		Delete(&models.LoginThrottle{})
+	return result.RowsAffected, result.Error
+}
+
+func toState(row models.LoginThrottle) State {
+	state := State{Failures: row.Failures, LastFailureAt: row.LastFailureAt}
+	if row.LockedUntil != nil {
+		state.LockedUntil = *row.LockedUntil
+	}
+	return state
+}
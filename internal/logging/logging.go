@@ -0,0 +1,91 @@
+// internal/logging/logging.go
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Options - настройки логгера приложения
+type Options struct {
+	Level  slog.Level
+	Format string // json | text
+	Policy Policy
+}
+
+// New - логгер с полями запроса из контекста и маскированием персональных данных.
+// Порядок: контекст -> маскирование -> вывод, поэтому поля запроса тоже проходят через политику
+func New(w io.Writer, opts Options) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	var out slog.Handler
+	if opts.Format == "text" {
+		out = slog.NewTextHandler(w, handlerOpts)
+	} else {
+		out = slog.NewJSONHandler(w, handlerOpts)
+	}
+	return slog.New(&contextHandler{next: NewRedactingHandler(out, opts.Policy)})
+}
+
+// ParseLevel - debug | info | warn | error
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+// ParseFormat - json | text
+func ParseFormat(s string) (string, error) {
+	switch f := strings.ToLower(s); f {
+	case "json", "text":
+		return f, nil
+	}
+	return "", fmt.Errorf("invalid log format %q: expected json or text", s)
+}
+
+type attrsKey struct{}
+
+// WithAttrs - поля, которые попадут в каждую запись с этим контекстом
+// (request_id, user_id, role). Добавляются к уже накопленным
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	prev, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	merged := make([]slog.Attr, 0, len(prev)+len(attrs))
+	merged = append(merged, prev...)
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+// contextHandler дописывает поля из WithAttrs и идентификаторы трассы
+type contextHandler struct {
+	next slog.Handler
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if attrs, ok := ctx.Value(attrsKey{}).([]slog.Attr); ok {
+			r.AddAttrs(attrs...)
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,235 @@
+// internal/logging/redact.go
+package logging
+
+import (
+	"ac-ai/internal/models"
+	"ac-ai/internal/money"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Mode - что делать с чувствительным значением
+type Mode string
+
+const (
+	ModeOff    Mode = "off"    // писать как есть (локальная отладка)
+	ModeMask   Mode = "mask"   // частично: j***@mail.kz, ~1e7 для сумм
+	ModeRedact Mode = "redact" // полностью заменить на [REDACTED]
+)
+
+const redacted = "[REDACTED]"
+
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(s)); m {
+	case ModeOff, ModeMask, ModeRedact:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid redaction mode %q: expected off, mask or redact", s)
+}
+
+// Policy - маскирование по категориям данных
+type Policy struct {
+	Emails  Mode // email в любом строковом поле и в тексте сообщения
+	Amounts Mode // суммы заявок: money.Amount, money.Money, поля *amount
+	Profile Mode // финансовый профиль: доход, платежи, кредитная история, возраст...
+}
+
+// DefaultPolicy - для продакшена
+var DefaultPolicy = Policy{Emails: ModeMask, Amounts: ModeMask, Profile: ModeRedact}
+
+// ParsePolicy - политика из строковых настроек (LOG_REDACT_*)
+func ParsePolicy(emails, amounts, profile string) (Policy, error) {
+	var p Policy
+	var err error
+	if p.Emails, err = ParseMode(emails); err != nil {
+		return Policy{}, fmt.Errorf("emails: %w", err)
+	}
+	if p.Amounts, err = ParseMode(amounts); err != nil {
+		return Policy{}, fmt.Errorf("amounts: %w", err)
+	}
+	if p.Profile, err = ParseMode(profile); err != nil {
+		return Policy{}, fmt.Errorf("profile: %w", err)
+	}
+	return p, nil
+}
+
+var emailRe = regexp.MustCompile(`([A-Za-z0-9._%+\-])[A-Za-z0-9._%+\-]*@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+// Ключи полей финансового профиля (и группы, внутри которых все считается профилем)
+var profileKeys = map[string]bool{
+	"profile": true, "financial_profile": true,
+	"income": true, "monthly_payments": true, "credit_history": true,
+	"job_experience_years": true, "age": true, "income_proof": true,
+}
+
+func isAmountKey(key string) bool {
+	return key == "amount" || strings.HasSuffix(key, "_amount")
+}
+
+// RedactingHandler маскирует атрибуты и текст сообщения перед передачей в next.
+// Категория определяется по типу значения (money.Amount, models.FinancialProfile)
+// и по имени ключа, так что новые поля с теми же именами маскируются без доработок
+type RedactingHandler struct {
+	next      slog.Handler
+	policy    Policy
+	inProfile bool // внутри группы profile
+}
+
+func NewRedactingHandler(next slog.Handler, policy Policy) *RedactingHandler {
+	return &RedactingHandler{next: next, policy: policy}
+}
+
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *RedactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, h.scrubEmails(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.redact(a, h.inProfile))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redactedAttrs := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redactedAttrs[i] = h.redact(a, h.inProfile)
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(redactedAttrs), policy: h.policy, inProfile: h.inProfile}
+}
+
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{
+		next:      h.next.WithGroup(name),
+		policy:    h.policy,
+		inProfile: h.inProfile || profileKeys[strings.ToLower(name)],
+	}
+}
+
+func (h *RedactingHandler) redact(a slog.Attr, inProfile bool) slog.Attr {
+	a.Value = a.Value.Resolve()
+	key := strings.ToLower(a.Key)
+	inProfile = inProfile || profileKeys[key]
+
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		out := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			out[i] = h.redact(ga, inProfile)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(out...)}
+	}
+
+	if a.Value.Kind() == slog.KindAny {
+		switch v := a.Value.Any().(type) {
+		case models.FinancialProfile:
+			return slog.Attr{Key: a.Key, Value: h.profile(&v)}
+		case *models.FinancialProfile:
+			return slog.Attr{Key: a.Key, Value: h.profile(v)}
+		case money.Amount:
+			return slog.Attr{Key: a.Key, Value: h.amount(v, "", h.amountMode(inProfile))}
+		case money.Money:
+			return slog.Attr{Key: a.Key, Value: h.amount(v.Amount, v.Currency, h.amountMode(inProfile))}
+		case error:
+			a.Value = slog.StringValue(v.Error())
+		}
+	}
+
+	switch {
+	case inProfile:
+		a.Value = maskScalar(a.Value, h.policy.Profile)
+	case isAmountKey(key):
+		a.Value = maskScalar(a.Value, h.policy.Amounts)
+	}
+	if a.Value.Kind() == slog.KindString {
+		a.Value = slog.StringValue(h.scrubEmails(a.Value.String()))
+	}
+	return a
+}
+
+// amountMode - суммы из профиля (доход, платежи) подчиняются политике профиля
+func (h *RedactingHandler) amountMode(inProfile bool) Mode {
+	if inProfile {
+		return h.policy.Profile
+	}
+	return h.policy.Amounts
+}
+
+func (h *RedactingHandler) amount(a money.Amount, currency money.Currency, mode Mode) slog.Value {
+	switch mode {
+	case ModeOff:
+		if currency != "" {
+			return slog.StringValue(money.New(a, currency).String())
+		}
+		return slog.StringValue(a.String())
+	case ModeMask:
+		s := magnitude(math.Abs(float64(a) / 100))
+		if currency != "" {
+			s += " " + string(currency)
+		}
+		return slog.StringValue(s)
+	}
+	return slog.StringValue(redacted)
+}
+
+func (h *RedactingHandler) profile(p *models.FinancialProfile) slog.Value {
+	if p == nil {
+		return slog.AnyValue(nil)
+	}
+	mode := h.policy.Profile
+	if mode == ModeRedact {
+		return slog.StringValue(redacted)
+	}
+	return slog.GroupValue(
+		slog.Uint64("id", uint64(p.ID)),
+		slog.Any("income", h.amount(p.Income, p.Currency, mode)),
+		slog.Any("monthly_payments", h.amount(p.MonthlyPayments, p.Currency, mode)),
+		slog.Any("credit_history", maskScalar(slog.StringValue(p.CreditHistory), mode)),
+		slog.Any("job_experience_years", maskScalar(slog.Float64Value(p.JobExperienceYears), mode)),
+		slog.Any("age", maskScalar(slog.IntValue(p.Age), mode)),
+		slog.Any("income_proof", maskScalar(slog.StringValue(p.IncomeProof), mode)),
+	)
+}
+
+// maskScalar: числа в режиме mask - только порядок величины, остальное - ***
+func maskScalar(v slog.Value, mode Mode) slog.Value {
+	switch mode {
+	case ModeOff:
+		return v
+	case ModeMask:
+		switch v.Kind() {
+		case slog.KindInt64:
+			return slog.StringValue(magnitude(math.Abs(float64(v.Int64()))))
+		case slog.KindUint64:
+			return slog.StringValue(magnitude(float64(v.Uint64())))
+		case slog.KindFloat64:
+			return slog.StringValue(magnitude(math.Abs(v.Float64())))
+		}
+		return slog.StringValue("***")
+	}
+	return slog.StringValue(redacted)
+}
+
+// magnitude - 15 000 000 -> ~1e7
+func magnitude(x float64) string {
+	if x < 1 {
+		return "~0"
+	}
+	return fmt.Sprintf("~1e%d", int(math.Floor(math.Log10(x))))
+}
+
+func (h *RedactingHandler) scrubEmails(s string) string {
+	switch h.policy.Emails {
+	case ModeOff:
+		return s
+	case ModeMask:
+		return emailRe.ReplaceAllString(s, "$1***@$2")
+	}
+	return emailRe.ReplaceAllString(s, redacted)
+}
@@ -0,0 +1,135 @@
+// internal/metrics/metrics.go
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Default - реестр приложения, который отдается на /metrics. Свой, а не
+// prometheus.DefaultRegisterer: в выдачу попадает только то, что зарегистрировали мы
+var Default = prometheus.NewRegistry()
+
+func init() {
+	Default.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler - GET /metrics
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Default, promhttp.HandlerOpts{})
+}
+
+// Бакеты латентности в секундах: от быстрых API-запросов до долгих ответов OpenAI
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 40}
+
+// Бакеты холодного балла: диапазон CalculateColdScore примерно от -500 до 800
+var coldScoreBuckets = []float64{-300, -200, -100, 0, 100, 200, 300, 400, 500, 600, 700, 800}
+
+// Каналы скоринга (метка product)
+const (
+	ProductClientChat = "client_chat" // /scoring/ask
+	ProductPartnerAPI = "partner_api" // /partner/scoring
+)
+
+// Векторы не экспортируются: метки передаются только через Observe*,
+// поэтому число значений меток всегда совпадает с объявленным
+var (
+	factory = promauto.With(Default)
+
+	httpRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by route template and status.",
+	}, []string{"method", "route", "status"})
+	httpDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route template and status.",
+		Buckets: latencyBuckets,
+	}, []string{"method", "route", "status"})
+
+	scoringDecisions = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "scoring_decisions_total",
+		Help: "Cold scoring decisions by outcome and product.",
+	}, []string{"decision", "product"})
+	coldScore = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scoring_cold_score",
+		Help:    "Distribution of cold scores.",
+		Buckets: coldScoreBuckets,
+	}, []string{"product"})
+
+	aiDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "ai_request_duration_seconds",
+		Help:    "AI provider call latency by model and operation.",
+		Buckets: latencyBuckets,
+	}, []string{"model", "operation"})
+	aiErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "ai_request_errors_total",
+		Help: "Failed AI provider calls by model and operation.",
+	}, []string{"model", "operation"})
+	aiTokens = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "ai_tokens_total",
+		Help: "Tokens consumed by model and kind (prompt, completion).",
+	}, []string{"model", "kind"})
+)
+
+// ObserveHTTP - завершенный HTTP-запрос. route - шаблон маршрута Gin
+func ObserveHTTP(method, route, status string, elapsed time.Duration) {
+	httpRequests.WithLabelValues(method, route, status).Inc()
+	httpDuration.WithLabelValues(method, route, status).Observe(elapsed.Seconds())
+}
+
+// ObserveScoring - решение холодного скоринга
+func ObserveScoring(product, decision string, score int) {
+	scoringDecisions.WithLabelValues(decision, product).Inc()
+	coldScore.WithLabelValues(product).Observe(float64(score))
+}
+
+// ObserveAI - вызов провайдера ИИ; tokens учитываются только для успешных вызовов
+func ObserveAI(model, operation string, started time.Time, err error, promptTokens, completionTokens int) {
+	aiDuration.WithLabelValues(model, operation).Observe(time.Since(started).Seconds())
+	if err != nil {
+		aiErrors.WithLabelValues(model, operation).Inc()
+		return
+	}
+	aiTokens.WithLabelValues(model, "prompt").Add(float64(promptTokens))
+	aiTokens.WithLabelValues(model, "completion").Add(float64(completionTokens))
+}
+
+// RegisterDBStats - статистика пула соединений database/sql (go_sql_*)
+func RegisterDBStats(db *sql.DB, dbName string) {
+	Default.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// RegisterReviewQueueDepth - число заявок в очереди ручной проверки. count вызывается
+// при каждом опросе; при ошибке метрика пропускается, а не отдается нулем
+func RegisterReviewQueueDepth(count func() (int64, error)) {
+	Default.MustRegister(&queueDepthCollector{
+		desc:  prometheus.NewDesc("review_queue_depth", "Applications waiting for a manual agent decision.", nil, nil),
+		count: count,
+	})
+}
+
+type queueDepthCollector struct {
+	desc  *prometheus.Desc
+	count func() (int64, error)
+}
+
+func (q *queueDepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- q.desc
+}
+
+func (q *queueDepthCollector) Collect(ch chan<- prometheus.Metric) {
+	n, err := q.count()
+	if err != nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(q.desc, prometheus.GaugeValue, float64(n))
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKey - ключ партнера для server-to-server запросов. Храним только SHA-256 хэш
+type APIKey struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	Name        string `gorm:"type:varchar(100);not null"`            // Название партнера/интеграции
+	Prefix      string `gorm:"type:varchar(16);uniqueIndex;not null"` // Видимая часть ключа (acai_xxxxxxxx)
+	KeyHash     string `gorm:"type:varchar(64);uniqueIndex;not null"`
+	Scopes      string `gorm:"type:text;not null"` // Через пробел, как scope в OAuth
+	RateLimit   int    `gorm:"not null"`           // Запросов в минуту
+	CreatedByID uint   `gorm:"not null"`
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+}
+
+// ScopeList - scopes ключа списком
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
@@ -2,6 +2,9 @@
 package models
 
 import (
+	"ac-ai/internal/money"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -17,22 +20,37 @@ const (
 	AgentStatusPending  = "PENDING"
 	AgentStatusApproved = "AGENT_APPROVED"
 	AgentStatusDenied   = "AGENT_DENIED"
+	// Агент запросил у клиента документ или пояснение; после ответа заявка
+	// возвращается в PENDING, а без ответа - отклоняется по истечении срока
+	AgentStatusInfoRequested = "INFO_REQUESTED"
 )
 
 type ScoringApplication struct {
 	gorm.Model
-	UserID          uint    `gorm:"not null"`
-	RequestedAmount float64 `gorm:"not null"`
+	UserID          uint         `gorm:"not null"`
+	RequestedAmount money.Amount `gorm:"type:numeric(20,2);not null"`
+
+	// Валюта кредита и сумма в базовой валюте по курсу на момент заявки (для статистики)
+	Currency            money.Currency `gorm:"type:varchar(3);not null;default:'KZT'"`
+	RequestedAmountBase money.Amount   `gorm:"type:numeric(20,2);not null"`
 
 	// Решение, которое принял ИИ / "холодный" скоринг
-	FinalDecision   string `gorm:"type:varchar(20);not null"` // APPROVED, DENIED, MANUAL_REVIEW
-	ColdScore       int
-	AIResponse      string `gorm:"type:text"` // Ответ, который увидел клиент
+	FinalDecision        string `gorm:"type:varchar(20);not null"` // APPROVED, DENIED, MANUAL_REVIEW
+	ColdScore            int
+	RecommendedMaxAmount money.Amount `gorm:"type:numeric(20,2)"` // Сколько скоринг готов был одобрить, в базовой валюте (для статистики)
+	AIResponse           string       `gorm:"type:text"`          // Ответ, который увидел клиент
 	// Поля для Агента
 	InternalReasons string `gorm:"type:text"` // JSON-массив []string с причинами для агента
-	
-	AgentStatus string `gorm:"type:varchar(20);default:'PENDING'"` // Статус, который выставил агент
-	AgentNotes  string `gorm:"type:text"`                          // Комментарий агента
 
-	User User `gorm:"foreignKey:UserID"` // Связь с пользователем
+	AgentStatus  string     `gorm:"type:varchar(20);default:'PENDING'"` // Статус, который выставил агент
+	AgentNotes   string     `gorm:"type:text"`                          // Комментарий агента
+	ReviewedByID *uint      `gorm:"index"`                              // Агент, принявший решение
+	ReviewedAt   *time.Time // Когда агент принял решение
+	// Агент, который взял заявку в работу (claim). Снимается при решении,
+	// запросе информации или release
+	ClaimedByID *uint `gorm:"index"`
+	ClaimedAt   *time.Time
+
+	User         User          `gorm:"foreignKey:UserID"` // Связь с пользователем
+	InfoRequests []InfoRequest `gorm:"foreignKey:ApplicationID"`
 }
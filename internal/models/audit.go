@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Действия, которые попадают в журнал аудита
+const (
+	AuditLoginLocked    = "login.locked"    // Аккаунт или IP заблокирован после серии неудачных входов
+	AuditLoginUnlocked  = "login.unlocked"  // Администратор снял блокировку
+	AuditSSOProvisioned = "sso.provisioned" // Учетная запись сотрудника создана или обновлена по данным IdP
+)
+
+// AuditEvent - запись журнала аудита безопасности
+type AuditEvent struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"index"`
+	Action    string    `gorm:"type:varchar(64);index;not null"`
+	ActorID   *uint     `gorm:"index"`                   // Кто выполнил действие (nil - система)
+	Subject   string    `gorm:"type:varchar(320);index"` // Ключ блокировки, email и т.п.
+	IP        string    `gorm:"type:varchar(64)"`
+	Details   string    `gorm:"type:text"`
+}
+
+// LoginThrottle - счетчик неудачных входов по аккаунту или IP (Postgres-хранилище блокировок)
+type LoginThrottle struct {
+	Key           string    `gorm:"type:varchar(320);primaryKey"`
+	Failures      int       `gorm:"not null"`
+	LastFailureAt time.Time `gorm:"not null;index"`
+	LockedUntil   *time.Time
+}
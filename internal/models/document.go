@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Типы документов, подтверждающих доход
+const (
+	DocumentKindSalaryCertificate = "salary_certificate"
+	DocumentKindBankStatement     = "bank_statement"
+	DocumentKindOther             = "other" // Вложения к ответу на запрос агента
+)
+
+// Статусы документа
+const (
+	DocumentStatusPendingReview = "PENDING_REVIEW" // Загружен и проверен антивирусом, ждет агента
+	DocumentStatusVerified      = "VERIFIED"
+	DocumentStatusRejected      = "REJECTED"
+	DocumentStatusInfected      = "INFECTED" // Отклонен антивирусом, файл не сохраняется
+)
+
+// DocumentProofLevel - какой уровень подтверждения дохода дает проверенный документ
+var DocumentProofLevel = map[string]string{
+	DocumentKindSalaryCertificate: IncomeProofOfficial,
+	DocumentKindBankStatement:     IncomeProofIndirect,
+}
+
+type Document struct {
+	gorm.Model
+	UserID      uint   `gorm:"index;not null"`
+	Kind        string `gorm:"type:varchar(30);not null"`
+	Filename    string `gorm:"type:varchar(255);not null"` // Исходное имя файла от клиента
+	ContentType string `gorm:"type:varchar(100);not null"`
+	Size        int64  `gorm:"not null"`
+	StorageKey  string `gorm:"type:varchar(255)"` // Ключ в BlobStore (пустой, если файл не сохранен)
+	// Если документ приложен к ответу на запрос агента
+	InfoRequestID *uint `gorm:"index"`
+
+	Status       string `gorm:"type:varchar(20);not null;index"`
+	ReviewNotes  string `gorm:"type:text"`
+	ReviewedByID *uint
+	ReviewedAt   *time.Time
+
+	User User `gorm:"foreignKey:UserID"`
+}
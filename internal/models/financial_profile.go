@@ -1,6 +1,8 @@
 package models
 
 import (
+	"ac-ai/internal/money"
+
 	"gorm.io/gorm"
 )
 
@@ -15,14 +17,27 @@ const (
 	IncomeProofVerbal   = "verbal"
 )
 
+// incomeProofRank - чем больше, тем надежнее подтверждение дохода
+var incomeProofRank = map[string]int{
+	IncomeProofVerbal:   1,
+	IncomeProofIndirect: 2,
+	IncomeProofOfficial: 3,
+}
+
+// IsStrongerIncomeProof - true, если уровень a надежнее уровня b
+func IsStrongerIncomeProof(a, b string) bool {
+	return incomeProofRank[a] > incomeProofRank[b]
+}
+
 type FinancialProfile struct {
 	gorm.Model
 	UserID uint `gorm:"uniqueIndex;not null"`
 
-	Income               float64 `gorm:"not null"`
-	MonthlyPayments      float64 `gorm:"not null"`
-	CreditHistory        string  `gorm:"type:varchar(20);not null"`
-	JobExperienceYears float64 `gorm:"not null"`
-	Age                  int     `gorm:"not null"`
-	IncomeProof          string  `gorm:"type:varchar(20);not null"`
-}
\ No newline at end of file
+	Income             money.Amount   `gorm:"type:numeric(20,2);not null"`
+	MonthlyPayments    money.Amount   `gorm:"type:numeric(20,2);not null"`
+	Currency           money.Currency `gorm:"type:varchar(3);not null;default:'KZT'"` // Валюта дохода и текущих платежей
+	CreditHistory      string         `gorm:"type:varchar(20);not null"`
+	JobExperienceYears float64        `gorm:"not null"`
+	Age                int            `gorm:"not null"`
+	IncomeProof        string         `gorm:"type:varchar(20);not null"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"ac-ai/internal/money"
+	"time"
+)
+
+// FXRate - курс валюты к базовой (money.BaseCurrency), который загружает казначейство
+type FXRate struct {
+	Currency   money.Currency `gorm:"type:varchar(3);primaryKey"`
+	RateToBase money.Rate     `gorm:"type:numeric(20,6);not null"` // Сколько тенге за единицу валюты
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Что агент просит у клиента
+const (
+	InfoRequestKindDocument      = "document"
+	InfoRequestKindClarification = "clarification"
+)
+
+// Статусы запроса информации
+const (
+	InfoRequestStatusOpen     = "OPEN"
+	InfoRequestStatusAnswered = "ANSWERED"
+	InfoRequestStatusExpired  = "EXPIRED"
+)
+
+// InfoRequest - запрос агента к клиенту по пограничной заявке
+type InfoRequest struct {
+	gorm.Model
+	ApplicationID uint      `gorm:"index;not null"`
+	AgentID       uint      `gorm:"not null"`
+	Kind          string    `gorm:"type:varchar(20);not null"`
+	Message       string    `gorm:"type:text;not null"`
+	Status        string    `gorm:"type:varchar(20);not null;index"`
+	ExpiresAt     time.Time `gorm:"not null;index"`
+
+	// Ответ клиента
+	ResponseText string `gorm:"type:text"`
+	RespondedAt  *time.Time
+	Attachments  []Document `gorm:"foreignKey:InfoRequestID"`
+
+	Application ScoringApplication `gorm:"foreignKey:ApplicationID"`
+}
@@ -0,0 +1,29 @@
+package models
+
+// Именованные права. Роуты проверяют права, а не роли.
+// Лежат рядом с ролями: ими проверяется ROLE_PERMISSIONS в конфиге
+const (
+	// Клиент
+	PermApplicationsCreate = "applications:create"
+	PermDocumentsUpload    = "documents:upload"
+	PermInfoRequestsAnswer = "info_requests:answer"
+
+	// Сотрудники
+	PermApplicationsRead   = "applications:read"
+	PermApplicationsDecide = "applications:decide"
+	PermClientsRead        = "clients:read"
+	PermDocumentsReview    = "documents:review"
+	PermStatsRead          = "stats:read"
+	PermExportsRun         = "exports:run"
+	PermStaffManage        = "staff:manage"
+	PermAccountsUnlock     = "accounts:unlock"
+	PermAPIKeysManage      = "api_keys:manage"
+)
+
+// AllPermissions - все известные права (для проверки конфигурации)
+var AllPermissions = []string{
+	PermApplicationsCreate, PermDocumentsUpload, PermInfoRequestsAnswer,
+	PermApplicationsRead, PermApplicationsDecide, PermClientsRead,
+	PermDocumentsReview, PermStatsRead, PermExportsRun, PermStaffManage,
+	PermAccountsUnlock, PermAPIKeysManage,
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken - одноразовый refresh-токен. Храним только SHA-256 хэш.
+// Все токены, выданные из одного логина, образуют "семью" (FamilyID):
+// повторное использование любого из них отзывает всю семью
+type RefreshToken struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UserID    uint       `gorm:"index;not null"`
+	FamilyID  string     `gorm:"type:varchar(32);index;not null"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time // Токен обменян на новый (ротация)
+	RevokedAt *time.Time // Семья отозвана (logout или обнаружено повторное использование)
+	// Access-токен, выданный в паре с этим refresh: при отзыве семьи его jti
+	// попадает в revoked_access_tokens
+	AccessJTI       *string `gorm:"type:varchar(32)"`
+	AccessExpiresAt *time.Time
+}
+
+// RevokedAccessToken - отозванный access-токен (по jti) до истечения его срока
+type RevokedAccessToken struct {
+	JTI       string    `gorm:"type:varchar(32);primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// OIDCLoginState - незавершенный вход через IdP: state (храним хэш), nonce и PKCE code_verifier.
+// Одноразовый: удаляется при возврате пользователя на callback
+type OIDCLoginState struct {
+	StateHash    string    `gorm:"type:varchar(64);primaryKey"`
+	Nonce        string    `gorm:"type:varchar(64);not null"`
+	CodeVerifier string    `gorm:"type:varchar(128);not null"`
+	ExpiresAt    time.Time `gorm:"not null;index"`
+	CreatedAt    time.Time
+}
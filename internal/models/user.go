@@ -1,23 +1,77 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 // Роли будем хранить как строки для простоты
 const (
-	RoleClient = "CLIENT"
-	RoleAgent  = "AGENT"
+	RoleClient     = "CLIENT"
+	RoleAgent      = "AGENT"
+	RoleSupervisor = "SUPERVISOR"
+	RoleAdmin      = "ADMIN"
+)
+
+// StaffRoles - роли сотрудников банка (выдаются только администратором)
+var StaffRoles = []string{RoleAgent, RoleSupervisor, RoleAdmin}
+
+// Статусы учетной записи
+const (
+	UserStatusInvited     = "INVITED" // Сотрудник приглашен, но еще не задал пароль
+	UserStatusActive      = "ACTIVE"
+	UserStatusDeactivated = "DEACTIVATED"
 )
 
 type User struct {
 	gorm.Model
 	Email        string `gorm:"type:varchar(100);uniqueIndex;not null"`
-	PasswordHash string `gorm:"not null"`
+	PasswordHash string `gorm:"not null" json:"-"`
 	Role         string `gorm:"type:varchar(10);not null"`
+	Status       string `gorm:"type:varchar(20);not null;default:'ACTIVE'"`
+	// Владение email подтверждено по ссылке из письма
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// Сотрудник входит через корпоративный IdP (OIDC "sub"). Пароль у таких учетных записей пустой
+	OIDCSubject *string `gorm:"type:varchar(255);uniqueIndex" json:"-"`
+
+	// TOTP (второй фактор). Секрет задается при подключении, а включается после подтверждения кодом
+	TOTPSecret    string     `gorm:"type:varchar(64)" json:"-"`
+	TOTPEnabledAt *time.Time `json:"-"`
+	TOTPLastStep  int64      `json:"-"` // Последний принятый шаг - повторно тот же код не пройдет
 
 	FinancialProfile FinancialProfile `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 
 	// --- ДОБАВЬТЕ ЭТУ СТРОКУ ---
 	ScoringApplications []ScoringApplication `gorm:"foreignKey:UserID"`
 }
+
+// StaffInvite - одноразовое приглашение сотрудника (храним только хэш токена)
+type StaffInvite struct {
+	ID          uint `gorm:"primarykey"`
+	CreatedAt   time.Time
+	UserID      uint      `gorm:"index;not null"`
+	InvitedByID uint      `gorm:"not null"`
+	TokenHash   string    `gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt   time.Time `gorm:"not null"`
+	UsedAt      *time.Time
+}
+
+// MFAEnabled - второй фактор подключен и подтвержден
+func (u *User) MFAEnabled() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// IsStaff - сотрудник банка (не клиент)
+func (u *User) IsStaff() bool {
+	return u.Role != RoleClient
+}
+
+// RecoveryCode - одноразовый код восстановления доступа при потере TOTP (храним хэш)
+type RecoveryCode struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UserID    uint   `gorm:"index;not null"`
+	CodeHash  string `gorm:"type:varchar(64);not null"`
+	UsedAt    *time.Time
+}
@@ -0,0 +1,256 @@
+// internal/money/money.go
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Scale - число знаков после запятой. У KZT (тиын), USD (цент) и RUB (копейка)
+// их два, поэтому шкала общая для всех поддерживаемых валют.
+const Scale = 2
+
+const unit = 100 // 10^Scale
+
+var (
+	ErrInvalidAmount = errors.New("invalid money amount")
+	ErrTooPrecise    = errors.New("money amount has more than 2 decimal places")
+)
+
+// Currency - код валюты ISO 4217
+type Currency string
+
+const (
+	KZT Currency = "KZT"
+	USD Currency = "USD"
+	RUB Currency = "RUB"
+)
+
+// BaseCurrency - валюта, в которой считается скоринг
+const BaseCurrency = KZT
+
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// supported - валюты, в которых принимаем заявки и доходы
+var supported = map[Currency]bool{KZT: true, USD: true, RUB: true}
+
+func (c Currency) Supported() bool {
+	return supported[c]
+}
+
+// Currencies - поддерживаемые валюты в алфавитном порядке (для документации API)
+func Currencies() []Currency {
+	out := make([]Currency, 0, len(supported))
+	for c := range supported {
+		out = append(out, c)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// ParseCurrency - код валюты в любом регистре ("usd" -> USD)
+func ParseCurrency(s string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(s)))
+	if !c.Supported() {
+		return "", fmt.Errorf("%w %q", ErrUnsupportedCurrency, s)
+	}
+	return c, nil
+}
+
+// Amount - сумма в минимальных единицах валюты (тиынах, центах, копейках).
+// В БД хранится как numeric(20,2), в JSON - как десятичное число.
+type Amount int64
+
+// Money - сумма вместе с валютой
+type Money struct {
+	Amount   Amount
+	Currency Currency
+}
+
+func New(amount Amount, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// FromMajor - сумма из целых единиц (тенге, долларов)
+func FromMajor(major int64) Amount {
+	return Amount(major * unit)
+}
+
+// Parse разбирает десятичную запись ("15000000", "1500.5", "-0.01") без потери точности
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	intPart, fracPart, hasDot := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" || hasDot && fracPart == "" {
+		return 0, ErrInvalidAmount
+	}
+	if len(fracPart) > Scale {
+		// Хвостовые нули точность не добавляют: 10.500 == 10.50
+		trimmed := strings.TrimRight(fracPart[Scale:], "0")
+		if trimmed != "" {
+			return 0, ErrTooPrecise
+		}
+		fracPart = fracPart[:Scale]
+	}
+	fracPart += strings.Repeat("0", Scale-len(fracPart))
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	for _, r := range intPart + fracPart {
+		if r < '0' || r > '9' {
+			return 0, ErrInvalidAmount
+		}
+	}
+	v, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+	if neg {
+		v = -v
+	}
+	return Amount(v), nil
+}
+
+// String - каноническая запись с двумя знаками: "15000000.00"
+func (a Amount) String() string {
+	v := int64(a)
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/unit, v%unit)
+}
+
+// Format - запись для людей: "15 000 000" или "1 500,50"
+func (a Amount) Format() string {
+	v := int64(a)
+	sign := ""
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+
+	digits := strconv.FormatInt(v/unit, 10)
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(r)
+	}
+	if frac := v % unit; frac != 0 {
+		fmt.Fprintf(&b, ",%02d", frac)
+	}
+	return b.String()
+}
+
+// MulRat умножает сумму на num/den с округлением половины от нуля.
+// Промежуточный результат считается в big.Int, чтобы не переполнить int64.
+func (a Amount) MulRat(num, den int64) Amount {
+	if den == 0 {
+		panic("money: division by zero")
+	}
+	p := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(num))
+	d := big.NewInt(den)
+	if d.Sign() < 0 {
+		p.Neg(p)
+		d.Neg(d)
+	}
+
+	q, r := new(big.Int).QuoRem(p, d, new(big.Int))
+	// |2r| >= d - округляем от нуля
+	if new(big.Int).Abs(new(big.Int).Lsh(r, 1)).Cmp(d) >= 0 {
+		if p.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Amount(q.Int64())
+}
+
+// Ratio - отношение двух сумм (например, DTI). Это уже не деньги, поэтому float64.
+func Ratio(a, b Amount) float64 {
+	if b == 0 {
+		return 0
+	}
+	return float64(a) / float64(b)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON принимает и число (1500.50), и строку ("1500.50")
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	s = strings.Trim(s, `"`)
+	if strings.ContainsAny(s, "eE") {
+		// 1.5e6 присылают некоторые JS-клиенты; лишние знаки после запятой Parse отвергнет
+		f, _, err := big.ParseFloat(s, 10, 128, big.ToNearestEven)
+		if err != nil {
+			return ErrInvalidAmount
+		}
+		s = f.Text('f', -1)
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Value пишет сумму в numeric как десятичную строку
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+func (a *Amount) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case []byte:
+		return a.scanString(string(v))
+	case string:
+		return a.scanString(v)
+	case int64:
+		*a = FromMajor(v)
+		return nil
+	case float64:
+		return a.scanString(strconv.FormatFloat(v, 'f', Scale, 64))
+	}
+	return fmt.Errorf("money: cannot scan %T", src)
+}
+
+func (a *Amount) scanString(s string) error {
+	v, err := Parse(s)
+	if err != nil {
+		return fmt.Errorf("money: scan %q: %w", s, err)
+	}
+	*a = v
+	return nil
+}
+
+func (m Money) String() string {
+	return m.Amount.String() + " " + string(m.Currency)
+}
+
+// Format - сумма с кодом валюты для людей: "15 000 000 KZT"
+func (m Money) Format() string {
+	return m.Amount.Format() + " " + string(m.Currency)
+}
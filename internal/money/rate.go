@@ -0,0 +1,92 @@
+// internal/money/rate.go
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RateScale - сколько знаков после запятой допускаем в курсе (5.123456 RUB)
+const RateScale = 6
+
+var (
+	ErrInvalidRate  = errors.New("invalid exchange rate")
+	ErrRateNotFound = errors.New("exchange rate not available")
+)
+
+// Rate - курс как точная дробь Num/Den: сколько единиц базовой валюты
+// стоит одна единица другой валюты (USD -> 470.25 KZT)
+type Rate struct {
+	Num int64
+	Den int64
+}
+
+// One - курс базовой валюты к самой себе
+var One = Rate{Num: 1, Den: 1}
+
+// ParseRate разбирает десятичную запись курса ("470.25")
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(s)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > RateScale {
+		return Rate{}, ErrInvalidRate
+	}
+
+	den := int64(1)
+	for range fracPart {
+		den *= 10
+	}
+	num, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil || num <= 0 {
+		return Rate{}, ErrInvalidRate
+	}
+	return Rate{Num: num, Den: den}, nil
+}
+
+func (r Rate) Valid() bool {
+	return r.Num > 0 && r.Den > 0
+}
+
+// ToBase переводит сумму в базовую валюту
+func (r Rate) ToBase(a Amount) Amount {
+	return a.MulRat(r.Num, r.Den)
+}
+
+// FromBase переводит сумму из базовой валюты
+func (r Rate) FromBase(a Amount) Amount {
+	return a.MulRat(r.Den, r.Num)
+}
+
+func (r Rate) String() string {
+	if r.Den == 1 {
+		return strconv.FormatInt(r.Num, 10)
+	}
+	digits := len(strconv.FormatInt(r.Den, 10)) - 1
+	return fmt.Sprintf("%d.%0*d", r.Num/r.Den, digits, r.Num%r.Den)
+}
+
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+func (r *Rate) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	default:
+		return fmt.Errorf("money: cannot scan rate from %T", src)
+	}
+	rate, err := ParseRate(s)
+	if err != nil {
+		return fmt.Errorf("money: scan rate %q: %w", s, err)
+	}
+	*r = rate
+	return nil
+}
@@ -0,0 +1,267 @@
+// internal/openapi/builder.go
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Схемы безопасности, на которые ссылаются маршруты
+const (
+	SecurityBearer = "bearerAuth"
+	SecurityAPIKey = "apiKeyAuth"
+)
+
+// Route - описание маршрута Gin. Типы запроса и ответа задаются значениями
+// структур из schemas: схема строится по их тегам json, form и binding
+type Route struct {
+	Summary      string
+	Description  string
+	Tag          string
+	Security     string   // SecurityBearer, SecurityAPIKey или пусто для публичных маршрутов
+	Query        any      // структура для ShouldBindQuery (теги form)
+	Body         any      // JSON-тело для ShouldBindJSON
+	BodyOptional bool     // тело можно не передавать (logout без refresh_token)
+	Form         any      // поля multipart-формы (теги form)
+	Files        []File   // файлы multipart-формы
+	Status       int      // код успешного ответа, по умолчанию 200
+	Response     any      // JSON успешного ответа (или OneOf); nil - без тела
+	ContentTypes []string // успешный ответ не в JSON: text/csv, text/event-stream...
+}
+
+// OneOf - ответ одного из нескольких типов (токены или MFA-челлендж при входе)
+type OneOf []any
+
+// File - файловое поле multipart-формы
+type File struct {
+	Name     string
+	Multiple bool
+	Required bool
+}
+
+// Spec - спецификация API: маршруты описываются вручную, а пути и методы
+// берутся из роутера Gin, поэтому документация не расходится с кодом
+type Spec struct {
+	Info            Info
+	Tags            []Tag
+	SecuritySchemes map[string]SecurityScheme
+	Routes          map[string]Route // ключ "METHOD /path" в синтаксисе Gin: "GET /api/v1/agent/clients/:id/documents"
+	Error           any              // тело ответа с ошибкой
+	Generator       *Generator
+}
+
+// Key - ключ маршрута в Spec.Routes
+func Key(method, path string) string {
+	return method + " " + path
+}
+
+// Build - документ по зарегистрированным маршрутам. missing - маршруты роутера
+// без описания в Routes. Описанные, но не зарегистрированные маршруты
+// (SSO без настроенного IdP, /metrics на отдельном порту) в документ не попадают
+func (s *Spec) Build(routes gin.RoutesInfo) (doc *Document, missing []string) {
+	g := s.Generator
+	if g == nil {
+		g = NewGenerator()
+	}
+	doc = &Document{
+		OpenAPI: Version,
+		Info:    s.Info,
+		Tags:    s.Tags,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			SecuritySchemes: s.SecuritySchemes,
+		},
+	}
+
+	var errorSchema *Schema
+	if s.Error != nil {
+		errorSchema = g.Schema(reflect.TypeOf(s.Error))
+	}
+
+	operationIDs := map[string]bool{}
+	for _, ri := range routes {
+		route, ok := s.Routes[Key(ri.Method, ri.Path)]
+		if !ok {
+			missing = append(missing, Key(ri.Method, ri.Path))
+			continue
+		}
+		op := g.operation(route, ri, errorSchema)
+		op.OperationID = uniqueID(operationID(ri), operationIDs)
+
+		path := openAPIPath(ri.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(ri.Method)] = op
+	}
+	doc.Components.Schemas = g.Components()
+	slices.Sort(missing)
+	return doc, missing
+}
+
+func (g *Generator) operation(route Route, ri gin.RouteInfo, errorSchema *Schema) *Operation {
+	op := &Operation{
+		Summary:     route.Summary,
+		Description: route.Description,
+		Responses:   map[string]Response{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+	if route.Security != "" {
+		op.Security = []map[string][]string{{route.Security: {}}}
+	}
+
+	for _, name := range pathParams(ri.Path) {
+		// Параметры пути в нашем API - числовые идентификаторы (strconv.ParseUint в хэндлерах)
+		op.Parameters = append(op.Parameters, Parameter{
+			Name: name, In: "path", Required: true,
+			Schema: &Schema{Type: "integer", Format: "int64", Minimum: ptr(1.0)},
+		})
+	}
+	if route.Query != nil {
+		for _, f := range g.Fields(reflect.TypeOf(route.Query), "form") {
+			op.Parameters = append(op.Parameters, Parameter{Name: f.Name, In: "query", Required: f.Required, Schema: f.Schema})
+		}
+	}
+
+	switch {
+	case route.Body != nil:
+		op.RequestBody = &RequestBody{
+			Required: !route.BodyOptional,
+			Content:  map[string]MediaType{"application/json": {Schema: g.Schema(reflect.TypeOf(route.Body))}},
+		}
+	case route.Form != nil || len(route.Files) > 0:
+		form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		if route.Form != nil {
+			for _, f := range g.Fields(reflect.TypeOf(route.Form), "form") {
+				form.Properties[f.Name] = f.Schema
+				if f.Required {
+					form.Required = append(form.Required, f.Name)
+				}
+			}
+		}
+		for _, f := range route.Files {
+			file := &Schema{Type: "string", Format: "binary"}
+			if f.Multiple {
+				file = &Schema{Type: "array", Items: file}
+			}
+			form.Properties[f.Name] = file
+			if f.Required {
+				form.Required = append(form.Required, f.Name)
+			}
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"multipart/form-data": {Schema: form}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	switch {
+	case len(route.ContentTypes) > 0:
+		success.Content = map[string]MediaType{}
+		for _, ct := range route.ContentTypes {
+			success.Content[ct] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+	case route.Response != nil:
+		success.Content = map[string]MediaType{"application/json": {Schema: g.responseSchema(route.Response)}}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	if errorSchema != nil {
+		errorResponse := func(description string) Response {
+			return Response{Description: description, Content: map[string]MediaType{"application/json": {Schema: errorSchema}}}
+		}
+		if op.RequestBody != nil || route.Query != nil || len(pathParams(ri.Path)) > 0 {
+			op.Responses["400"] = errorResponse("Invalid request: see error.code and error.fields")
+		}
+		if route.Security != "" {
+			op.Responses["401"] = errorResponse("Missing or invalid credentials")
+			op.Responses["403"] = errorResponse("Not enough permissions")
+		}
+		op.Responses["default"] = errorResponse("Error")
+	}
+	return op
+}
+
+func (g *Generator) responseSchema(v any) *Schema {
+	variants, ok := v.(OneOf)
+	if !ok {
+		return g.Schema(reflect.TypeOf(v))
+	}
+	s := &Schema{}
+	for _, variant := range variants {
+		s.OneOf = append(s.OneOf, g.Schema(reflect.TypeOf(variant)))
+	}
+	return s
+}
+
+var pathParamRe = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// openAPIPath - /staff/:id/role -> /staff/{id}/role
+func openAPIPath(path string) string {
+	return pathParamRe.ReplaceAllString(path, "{$1}")
+}
+
+func pathParams(path string) []string {
+	var out []string
+	for _, m := range pathParamRe.FindAllStringSubmatch(path, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
+
+// operationID - по имени хэндлера: handlers.(*AgentHandler).GetStats-fm -> agentGetStats.
+// Для замыканий и обернутых http.Handler - по методу и пути
+func operationID(ri gin.RouteInfo) string {
+	name := strings.TrimSuffix(ri.Handler, "-fm")
+	if i := strings.LastIndex(name, "(*"); i >= 0 {
+		recv, method, ok := strings.Cut(name[i+2:], ").")
+		if ok {
+			return lowerFirst(strings.TrimSuffix(recv, "Handler")) + method
+		}
+	}
+
+	id := strings.ToLower(ri.Method)
+	for _, part := range strings.FieldsFunc(ri.Path, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		id += upperFirst(part)
+	}
+	if ri.Path == "/" {
+		id += "Root"
+	}
+	return id
+}
+
+func uniqueID(id string, seen map[string]bool) string {
+	out := id
+	for i := 2; seen[out]; i++ {
+		out = fmt.Sprintf("%s%d", id, i)
+	}
+	seen[out] = true
+	return out
+}
+
+// lowerFirst - первое слово в нижний регистр, аббревиатуры целиком: APIKey -> apiKey, SSO -> sso
+func lowerFirst(s string) string {
+	r := []rune(s)
+	for i := range r {
+		if !unicode.IsUpper(r[i]) || i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
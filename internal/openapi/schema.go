@@ -0,0 +1,374 @@
+// internal/openapi/schema.go
+package openapi
+
+import (
+	"ac-ai/internal/money"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Generator строит JSON Schema по Go-типам. Именованные структуры попадают
+// в components/schemas и подставляются ссылкой $ref
+type Generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+	custom  map[reflect.Type]*Schema
+}
+
+func NewGenerator() *Generator {
+	return &Generator{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+		custom:  map[reflect.Type]*Schema{},
+	}
+}
+
+// Name задает имя схемы типа вместо имени Go-типа (apierror.Body -> "Error")
+func (g *Generator) Name(v any, name string) {
+	g.names[reflect.TypeOf(v)] = name
+}
+
+// Define - готовая схема для типа, который сам пишет свой JSON (gorm.DeletedAt)
+func (g *Generator) Define(v any, s *Schema) {
+	g.custom[reflect.TypeOf(v)] = s
+}
+
+// Components - накопленные именованные схемы
+func (g *Generator) Components() map[string]*Schema {
+	return g.schemas
+}
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	amountType   = reflect.TypeFor[money.Amount]()
+	currencyType = reflect.TypeFor[money.Currency]()
+)
+
+// Schema - схема JSON-тела для типа t
+func (g *Generator) Schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		return nullable(g.Schema(t.Elem()))
+	}
+	if s, ok := g.custom[t]; ok {
+		c := *s
+		return &c
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case amountType:
+		// Сумма с двумя знаками после запятой; в JSON - число, на входе допускается и строка
+		return &Schema{Type: "number", Format: "decimal", MultipleOf: ptr(math.Pow10(-money.Scale))}
+	case currencyType:
+		return &Schema{Type: "string", Enum: currencyEnum()}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t, "json")
+		}
+		name := g.typeName(t)
+		if _, ok := g.schemas[name]; !ok {
+			g.schemas[name] = &Schema{} // заглушка на случай рекурсивных типов
+			g.schemas[name] = g.object(t, "json")
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.Schema(t.Elem())}
+	case reflect.Interface:
+		return &Schema{} // any: любое значение
+	}
+	return scalar(t)
+}
+
+// object - схема структуры. tag - "json" для тела запроса и ответа, "form" для форм
+func (g *Generator) object(t reflect.Type, tag string) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, f := range g.Fields(t, tag) {
+		s.Properties[f.Name] = f.Schema
+		if f.Required {
+			s.Required = append(s.Required, f.Name)
+		}
+	}
+	return s
+}
+
+// Field - поле структуры с учетом тегов json/form и правил binding
+type Field struct {
+	Name     string
+	Schema   *Schema
+	Required bool
+}
+
+// Fields - поля структуры в порядке объявления. Встроенные структуры без имени
+// раскрываются, как это делают encoding/json и binding Gin
+func (g *Generator) Fields(t reflect.Type, tag string) []Field {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// Go-имя поля -> имя в JSON, для правил вида required_without=RecoveryCode
+	names := map[string]string{}
+	for i := 0; i < t.NumField(); i++ {
+		if name, ok := fieldName(t.Field(i), tag); ok {
+			names[t.Field(i).Name] = name
+		}
+	}
+
+	var out []Field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, ok := fieldName(sf, tag)
+		if !ok {
+			continue
+		}
+		if sf.Anonymous && sf.Tag.Get(tag) == "" && derefStruct(sf.Type) {
+			out = append(out, g.Fields(sf.Type, tag)...)
+			continue
+		}
+
+		s := g.Schema(sf.Type)
+		if tag == "form" {
+			_, opts, _ := strings.Cut(sf.Tag.Get("form"), ",")
+			if def, ok := strings.CutPrefix(opts, "default="); ok {
+				s.Default = parseValue(def, sf.Type)
+			}
+			if sf.Type == timeType && sf.Tag.Get("time_format") == "2006-01-02" {
+				s.Format = "date"
+			}
+		}
+		required := applyBinding(s, sf.Type, sf.Tag.Get("binding"), names)
+		out = append(out, Field{Name: name, Schema: s, Required: required})
+	}
+	return out
+}
+
+func fieldName(sf reflect.StructField, tag string) (string, bool) {
+	if !sf.IsExported() {
+		return "", false
+	}
+	name, _, _ := strings.Cut(sf.Tag.Get(tag), ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = sf.Name
+	}
+	return name, true
+}
+
+func derefStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// typeName - имя схемы: заданное через Name или имя Go-типа.
+// Для обобщенных типов page[schemas.ApplicationOut] -> PageApplicationOut
+func (g *Generator) typeName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	base, args, generic := strings.Cut(t.Name(), "[")
+	name := upperFirst(base)
+	if generic {
+		for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+			arg = arg[strings.LastIndex(arg, ".")+1:]
+			name += upperFirst(arg)
+		}
+	}
+	return name
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func scalar(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Minimum: ptr(0.0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	}
+	return &Schema{}
+}
+
+// nullable - указатель может прийти как null. Рядом с $ref в OpenAPI 3.0
+// другие ключи игнорируются, поэтому ссылку оборачиваем в allOf
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	s.Nullable = true
+	return s
+}
+
+func currencyEnum() []any {
+	var out []any
+	for _, c := range money.Currencies() {
+		out = append(out, string(c))
+	}
+	return out
+}
+
+// applyBinding переносит правила из тега binding в ограничения схемы.
+// Возвращает true, если поле обязательное
+func applyBinding(s *Schema, t reflect.Type, tag string, names map[string]string) bool {
+	if tag == "" {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	required := false
+	target, targetType := s, t
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = required || target == s
+		case "required_without":
+			field := names[param]
+			if field == "" {
+				field = param
+			}
+			target.Description = strings.TrimSpace(target.Description + " Required unless `" + field + "` is set.")
+		case "dive":
+			// Следующие правила относятся к элементам массива
+			if target.Items != nil {
+				target, targetType = target.Items, targetType.Elem()
+			}
+		case "min", "gte":
+			setLower(target, targetType, param, false)
+		case "gt":
+			setLower(target, targetType, param, true)
+		case "max", "lte":
+			setUpper(target, targetType, param, false)
+		case "lt":
+			setUpper(target, targetType, param, true)
+		case "len":
+			setLower(target, targetType, param, false)
+			setUpper(target, targetType, param, false)
+		case "oneof":
+			target.Enum = nil
+			for _, v := range strings.Fields(param) {
+				target.Enum = append(target.Enum, parseValue(v, targetType))
+			}
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		case "ip":
+			target.Format = "ip"
+		case "numeric":
+			target.Pattern = `^[0-9]+$`
+		case "currency":
+			target.Enum = currencyEnum()
+		case "password":
+			target.Format = "password"
+			target.Description = strings.TrimSpace(target.Description + " Must satisfy the password policy.")
+		}
+	}
+	return required
+}
+
+// setLower - min/gte/gt: длина для строк, число элементов для массивов, значение для чисел
+func setLower(s *Schema, t reflect.Type, param string, exclusive bool) {
+	switch t.Kind() {
+	case reflect.String:
+		n, _ := strconv.Atoi(param)
+		if exclusive {
+			n++
+		}
+		s.MinLength = &n
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, _ := strconv.Atoi(param)
+		if exclusive {
+			n++
+		}
+		s.MinItems = &n
+	default:
+		s.Minimum = ptr(bound(param, t))
+		s.ExclusiveMinimum = exclusive
+	}
+}
+
+func setUpper(s *Schema, t reflect.Type, param string, exclusive bool) {
+	switch t.Kind() {
+	case reflect.String:
+		n, _ := strconv.Atoi(param)
+		if exclusive {
+			n--
+		}
+		s.MaxLength = &n
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, _ := strconv.Atoi(param)
+		if exclusive {
+			n--
+		}
+		s.MaxItems = &n
+	default:
+		s.Maximum = ptr(bound(param, t))
+		s.ExclusiveMaximum = exclusive
+	}
+}
+
+// bound - числовая граница правила. Validator сравнивает money.Amount как int64,
+// то есть в тиынах, а в JSON сумма - в основных единицах
+func bound(param string, t reflect.Type) float64 {
+	v, _ := strconv.ParseFloat(param, 64)
+	if t == amountType {
+		v /= math.Pow10(money.Scale)
+	}
+	return v
+}
+
+// parseValue - значение из тега (default=10, oneof=1 2) в типе поля
+func parseValue(v string, t reflect.Type) any {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"ac-ai/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Как часто обновлять last_used_at (чтобы не писать в БД на каждый запрос)
+const apiKeyTouchInterval = time.Minute
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) CreateAPIKey(name, prefix, keyHash string, scopes []string, rateLimit int, createdByID uint) (*models.APIKey, error) {
+	key := models.APIKey{
+		Name:        name,
+		Prefix:      prefix,
+		KeyHash:     keyHash,
+		Scopes:      strings.Join(scopes, " "),
+		RateLimit:   rateLimit,
+		CreatedByID: createdByID,
+	}
+	if err := r.db.Create(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAPIKeys - все ключи, включая отозванные (для аудита)
+func (r *APIKeyRepository) GetAPIKeys() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Order("created_at desc").Find(&keys).Error
+	return keys, err
+}
+
+// GetActiveAPIKey - ключ по хэшу, если он не отозван
+func (r *APIKeyRepository) GetActiveAPIKey(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.Where("key_hash = ? AND revoked_at IS NULL", keyHash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// RevokeAPIKey - отзыв необратим: партнеру выдается новый ключ
+func (r *APIKeyRepository) RevokeAPIKey(id uint) (*models.APIKey, error) {
+	result := r.db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var key models.APIKey
+	if err := r.db.First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// TouchAPIKey - отметка последнего использования, не чаще раза в минуту
+func (r *APIKeyRepository) TouchAPIKey(id uint) error {
+	now := time.Now()
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-apiKeyTouchInterval)).
+		Update("last_used_at", now).Error
+}
@@ -1,23 +1,63 @@
 package repository
 
 import (
+	"ac-ai/internal/events"
 	"ac-ai/internal/models"
 	"ac-ai/internal/schemas"
+	"context"
+	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type ApplicationRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	events events.Publisher
 }
 
+var (
+	// ErrApplicationNotPending - заявка не найдена в очереди (уже решена или не требует агента)
+	ErrApplicationNotPending = errors.New("application is not pending manual review")
+	// ErrApplicationClaimed - заявку взял в работу другой агент
+	ErrApplicationClaimed = errors.New("application is claimed by another agent")
+	// ErrApplicationNotClaimed - release заявки, которую агент не брал
+	ErrApplicationNotClaimed = errors.New("application is not claimed by this agent")
+)
+
+// ApplicationClaimTTL - сколько заявка закреплена за агентом без продления.
+// После этого брошенную заявку может взять или решить другой агент
+const ApplicationClaimTTL = 30 * time.Minute
+
+// Размер пачки при потоковой выгрузке
+const exportBatchSize = 500
+
 type PaginatedApplicationsResult struct {
 	Applications []models.ScoringApplication
 	TotalItems   int64
 }
 
-func NewApplicationRepository(db *gorm.DB) *ApplicationRepository {
-	return &ApplicationRepository{db: db}
+func NewApplicationRepository(db *gorm.DB, publisher events.Publisher) *ApplicationRepository {
+	return &ApplicationRepository{db: db, events: publisher}
+}
+
+// WithContext - копия репозитория, запросы которой идут с ctx (отмена и трассировка)
+func (r *ApplicationRepository) WithContext(ctx context.Context) *ApplicationRepository {
+	return &ApplicationRepository{db: r.db.WithContext(ctx), events: r.events}
+}
+
+// ReviewQueueScope - заявки, которые ждут ручного решения агента.
+// Используется и в дашборде, и в выгрузке, чтобы фильтры не расходились
+func ReviewQueueScope(db *gorm.DB) *gorm.DB {
+	return db.Where("final_decision = ? AND agent_status = ?", models.StatusManualReview, models.AgentStatusPending)
+}
+
+// claimableBy - заявка свободна, закреплена за этим агентом или закрепление истекло
+func claimableBy(agentID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(claimed_by_id IS NULL OR claimed_by_id = ? OR claimed_at < ?)",
+			agentID, time.Now().Add(-ApplicationClaimTTL))
+	}
 }
 
 // CreateApplication - Вызывается хэндлером клиента при подаче заявки
@@ -25,6 +65,123 @@ func (r *ApplicationRepository) CreateApplication(app *models.ScoringApplication
 	return r.db.Create(app).Error
 }
 
+// SetAgentDecision - Вызывается агентом при ручном решении по заявке
+// Обновляет только заявки из очереди, чтобы два агента не перезаписали друг друга,
+// и только свободные или взятые в работу этим агентом
+func (r *ApplicationRepository) SetAgentDecision(appID, agentID uint, status, notes string) (*models.ScoringApplication, error) {
+	now := time.Now()
+	result := r.db.Model(&models.ScoringApplication{}).
+		Where("id = ?", appID).
+		Scopes(ReviewQueueScope, claimableBy(agentID)).
+		Updates(map[string]any{
+			"agent_status":   status,
+			"agent_notes":    notes,
+			"reviewed_by_id": agentID,
+			"reviewed_at":    now,
+			"claimed_by_id":  nil,
+			"claimed_at":     nil,
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, r.claimConflict(appID, ErrApplicationClaimed)
+	}
+
+	app, err := r.getForAgent(appID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.events.Publish(events.Event{
+		Type:          events.ApplicationDecided,
+		ApplicationID: app.ID,
+		FinalDecision: app.FinalDecision,
+		AgentStatus:   app.AgentStatus,
+		AgentID:       app.ReviewedByID,
+	})
+	return app, nil
+}
+
+// ClaimApplication - агент берет заявку из очереди в работу. Повторный claim
+// тем же агентом продлевает закрепление на ApplicationClaimTTL
+func (r *ApplicationRepository) ClaimApplication(appID, agentID uint) (*models.ScoringApplication, error) {
+	result := r.db.Model(&models.ScoringApplication{}).
+		Where("id = ?", appID).
+		Scopes(ReviewQueueScope, claimableBy(agentID)).
+		Updates(map[string]any{"claimed_by_id": agentID, "claimed_at": time.Now()})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, r.claimConflict(appID, ErrApplicationClaimed)
+	}
+	return r.publishClaim(appID, agentID, events.ApplicationClaimed)
+}
+
+// ReleaseApplication - агент возвращает взятую им заявку в общую очередь
+func (r *ApplicationRepository) ReleaseApplication(appID, agentID uint) (*models.ScoringApplication, error) {
+	result := r.db.Model(&models.ScoringApplication{}).
+		Where("id = ? AND claimed_by_id = ?", appID, agentID).
+		Scopes(ReviewQueueScope).
+		Updates(map[string]any{"claimed_by_id": nil, "claimed_at": nil})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, r.claimConflict(appID, ErrApplicationNotClaimed)
+	}
+	return r.publishClaim(appID, agentID, events.ApplicationReleased)
+}
+
+func (r *ApplicationRepository) publishClaim(appID, agentID uint, eventType string) (*models.ScoringApplication, error) {
+	app, err := r.getForAgent(appID)
+	if err != nil {
+		return nil, err
+	}
+	r.events.Publish(events.Event{
+		Type:          eventType,
+		ApplicationID: app.ID,
+		FinalDecision: app.FinalDecision,
+		AgentStatus:   app.AgentStatus,
+		AgentID:       &agentID,
+	})
+	return app, nil
+}
+
+// claimConflict - почему условное обновление не затронуло заявку: она ушла
+// из очереди (ErrApplicationNotPending) или дело в закреплении (claimErr)
+func (r *ApplicationRepository) claimConflict(appID uint, claimErr error) error {
+	var count int64
+	err := r.db.Model(&models.ScoringApplication{}).
+		Where("id = ?", appID).
+		Scopes(ReviewQueueScope).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrApplicationNotPending
+	}
+	return claimErr
+}
+
+// getForAgent - заявка со всем, что показывается агенту
+func (r *ApplicationRepository) getForAgent(appID uint) (*models.ScoringApplication, error) {
+	var app models.ScoringApplication
+	if err := r.db.Preload("User").Preload("InfoRequests.Attachments").First(&app, appID).Error; err != nil {
+		return nil, err
+	}
+	return &app, nil
+}
+
+// CountReviewQueue - глубина очереди ручной проверки (для метрик)
+func (r *ApplicationRepository) CountReviewQueue(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.ScoringApplication{}).Scopes(ReviewQueueScope).Count(&count).Error
+	return count, err
+}
+
 // GetApplicationsForReview - Вызывается агентом (главный дашборд)
 // Показывает заявки, требующие ручного решения
 func (r *ApplicationRepository) GetApplicationsForReview(pagination schemas.PaginationQuery) (*PaginatedApplicationsResult, error) {
@@ -32,8 +189,7 @@ func (r *ApplicationRepository) GetApplicationsForReview(pagination schemas.Pagi
 	var totalItems int64
 
 	// Сначала считаем общее количество (для пагинации)
-	baseQuery := r.db.Model(&models.ScoringApplication{}).
-		Where("final_decision = ? AND agent_status = ?", models.StatusManualReview, models.AgentStatusPending)
+	baseQuery := r.db.Model(&models.ScoringApplication{}).Scopes(ReviewQueueScope)
 
 	if err := baseQuery.Count(&totalItems).Error; err != nil {
 		return nil, err
@@ -42,6 +198,7 @@ func (r *ApplicationRepository) GetApplicationsForReview(pagination schemas.Pagi
 	// Теперь получаем нужную "страницу"
 	err := baseQuery.
 		Preload("User").
+		Preload("InfoRequests.Attachments").
 		Order("created_at desc").
 		Scopes(PaginateScope(pagination.Page, pagination.Limit)). // <-- Применяем пагинацию
 		Find(&applications).Error
@@ -69,6 +226,7 @@ func (r *ApplicationRepository) GetAllApplications(pagination schemas.Pagination
 	
 	err := baseQuery.
 		Preload("User").
+		Preload("InfoRequests.Attachments").
 		Order("created_at desc").
 		Scopes(PaginateScope(pagination.Page, pagination.Limit)).
 		Find(&applications).Error
@@ -81,4 +239,20 @@ func (r *ApplicationRepository) GetAllApplications(pagination schemas.Pagination
 		Applications: applications,
 		TotalItems:   totalItems,
 	}, nil
-}
\ No newline at end of file
+}
+
+// StreamApplications - Вызывается при выгрузке в CSV/XLSX
+// Отдает заявки пачками по exportBatchSize, не загружая всю таблицу в память
+func (r *ApplicationRepository) StreamApplications(view string, fn func([]models.ScoringApplication) error) error {
+	var batch []models.ScoringApplication
+
+	query := r.db.Model(&models.ScoringApplication{}).Preload("User")
+	if view == schemas.ApplicationViewReview {
+		query = query.Scopes(ReviewQueueScope)
+	}
+
+	// FindInBatches идет по первичному ключу, поэтому порядок - по id
+	return query.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
@@ -0,0 +1,133 @@
+// internal/repository/application_stats.go
+package repository
+
+import (
+	"ac-ai/internal/models"
+	"ac-ai/internal/money"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CountByKey - одна строка GROUP BY (решение или статус -> количество)
+type CountByKey struct {
+	Key   string
+	Count int64
+}
+
+// AgentThroughput - сколько решений принял агент за период
+type AgentThroughput struct {
+	AgentID  uint
+	Email    string
+	Decided  int64
+	Approved int64
+	Denied   int64
+}
+
+// ApplicationStats - "сырые" агрегаты для дашборда агента
+type ApplicationStats struct {
+	TotalItems              int64
+	ByFinalDecision         []CountByKey
+	ByAgentStatus           []CountByKey
+	ApprovedItems           int64
+	AvgColdScore            float64
+	AvgRequestedAmount      money.Amount
+	AvgRecommendedMaxAmount money.Amount
+	// Возраст заявок в очереди (в секундах), считается на текущий момент
+	QueueSize        int64
+	QueueAgeP50      float64
+	QueueAgeP90      float64
+	QueueAgeP99      float64
+	AgentThroughputs []AgentThroughput
+}
+
+// GetStats - Вызывается агентом (дашборд статистики)
+// Все агрегаты считаются на стороне Postgres, заявки в память не загружаются.
+// Период [from, to) применяется к created_at, а для агентов - к reviewed_at
+func (r *ApplicationRepository) GetStats(ctx context.Context, from, to time.Time) (*ApplicationStats, error) {
+	var stats ApplicationStats
+	db := r.db.WithContext(ctx)
+
+	// Session делает базовый запрос переиспользуемым для нескольких агрегатов
+	inPeriod := db.Model(&models.ScoringApplication{}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Session(&gorm.Session{})
+
+	// 1. Количество по решению скоринга и по статусу агента
+	if err := inPeriod.
+		Select("final_decision AS key, COUNT(*) AS count").
+		Group("final_decision").
+		Scan(&stats.ByFinalDecision).Error; err != nil {
+		return nil, err
+	}
+	if err := inPeriod.
+		Select("agent_status AS key, COUNT(*) AS count").
+		Group("agent_status").
+		Scan(&stats.ByAgentStatus).Error; err != nil {
+		return nil, err
+	}
+
+	// 2. Итоги и средние. Одобренной считаем заявку, одобренную скорингом или агентом
+	var totals struct {
+		TotalItems              int64
+		ApprovedItems           int64
+		AvgColdScore            float64
+		AvgRequestedAmount      money.Amount
+		AvgRecommendedMaxAmount money.Amount
+	}
+	if err := inPeriod.
+		Select(`COUNT(*) AS total_items,
+			COUNT(*) FILTER (WHERE final_decision = ? OR agent_status = ?) AS approved_items,
+			COALESCE(AVG(cold_score), 0) AS avg_cold_score,
+			COALESCE(ROUND(AVG(requested_amount_base), 2), 0) AS avg_requested_amount,
+			COALESCE(ROUND(AVG(recommended_max_amount), 2), 0) AS avg_recommended_max_amount`,
+			models.StatusApproved, models.AgentStatusApproved).
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	stats.TotalItems = totals.TotalItems
+	stats.ApprovedItems = totals.ApprovedItems
+	stats.AvgColdScore = totals.AvgColdScore
+	stats.AvgRequestedAmount = totals.AvgRequestedAmount
+	stats.AvgRecommendedMaxAmount = totals.AvgRecommendedMaxAmount
+
+	// 3. Перцентили возраста очереди (без фильтра по периоду - это "сейчас")
+	var queue struct {
+		QueueSize int64
+		P50       float64
+		P90       float64
+		P99       float64
+	}
+	if err := db.Model(&models.ScoringApplication{}).
+		Scopes(ReviewQueueScope).
+		Select(`COUNT(*) AS queue_size,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (NOW() - created_at))), 0) AS p50,
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (NOW() - created_at))), 0) AS p90,
+			COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM (NOW() - created_at))), 0) AS p99`).
+		Scan(&queue).Error; err != nil {
+		return nil, err
+	}
+	stats.QueueSize = queue.QueueSize
+	stats.QueueAgeP50 = queue.P50
+	stats.QueueAgeP90 = queue.P90
+	stats.QueueAgeP99 = queue.P99
+
+	// 4. Производительность агентов за период
+	if err := db.Model(&models.ScoringApplication{}).
+		Joins("JOIN users ON users.id = scoring_applications.reviewed_by_id").
+		Where("scoring_applications.reviewed_at >= ? AND scoring_applications.reviewed_at < ?", from, to).
+		Select(`scoring_applications.reviewed_by_id AS agent_id,
+			users.email AS email,
+			COUNT(*) AS decided,
+			COUNT(*) FILTER (WHERE scoring_applications.agent_status = ?) AS approved,
+			COUNT(*) FILTER (WHERE scoring_applications.agent_status = ?) AS denied`,
+			models.AgentStatusApproved, models.AgentStatusDenied).
+		Group("scoring_applications.reviewed_by_id, users.email").
+		Order("decided desc").
+		Scan(&stats.AgentThroughputs).Error; err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"ac-ai/internal/models"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// Record - пишет событие аудита. Ошибка записи не должна ломать основной запрос,
+// поэтому она только логируется
+func (r *AuditRepository) Record(event *models.AuditEvent) {
+	if err := r.db.Create(event).Error; err != nil {
+		slog.Error("Failed to write audit event", slog.String("action", event.Action), slog.String("subject", event.Subject), slog.Any("error", err))
+	}
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"ac-ai/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrDocumentNotPending - документ уже проверен (или отклонен антивирусом)
+var ErrDocumentNotPending = errors.New("document is not pending review")
+
+type DocumentRepository struct {
+	db *gorm.DB
+}
+
+func NewDocumentRepository(db *gorm.DB) *DocumentRepository {
+	return &DocumentRepository{db: db}
+}
+
+func (r *DocumentRepository) CreateDocument(doc *models.Document) error {
+	return r.db.Create(doc).Error
+}
+
+// DeleteDocument - удаляет запись насовсем (без soft delete): используется
+// только для документов, которые так и не были никуда приложены
+func (r *DocumentRepository) DeleteDocument(docID uint) error {
+	return r.db.Unscoped().Delete(&models.Document{}, docID).Error
+}
+
+func (r *DocumentRepository) GetDocumentByID(docID uint) (*models.Document, error) {
+	var doc models.Document
+	if err := r.db.Preload("User").First(&doc, docID).Error; err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// GetDocumentsByUser - документы клиента, новые сверху
+func (r *DocumentRepository) GetDocumentsByUser(userID uint) ([]models.Document, error) {
+	var docs []models.Document
+	err := r.db.Where("user_id = ?", userID).Order("created_at desc").Find(&docs).Error
+	return docs, err
+}
+
+// ReviewDocument - Вызывается агентом после просмотра документа
+// При подтверждении повышает IncomeProof в профиле клиента (но никогда не понижает)
+func (r *DocumentRepository) ReviewDocument(docID, agentID uint, status, notes string) (*models.Document, error) {
+	var doc models.Document
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 1. Меняем статус только у документа, который ждет проверки
+		result := tx.Model(&models.Document{}).
+			Where("id = ? AND status = ?", docID, models.DocumentStatusPendingReview).
+			Updates(map[string]any{
+				"status":         status,
+				"review_notes":   notes,
+				"reviewed_by_id": agentID,
+				"reviewed_at":    time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDocumentNotPending
+		}
+
+		if err := tx.First(&doc, docID).Error; err != nil {
+			return err
+		}
+
+		// 2. Повышаем уровень подтверждения дохода в профиле
+		if status != models.DocumentStatusVerified {
+			return nil
+		}
+		level, ok := models.DocumentProofLevel[doc.Kind]
+		if !ok {
+			return nil
+		}
+
+		// Клиент мог зарегистрироваться без profile_data: повышать нечего,
+		// документ все равно остается подтвержденным
+		var profile models.FinancialProfile
+		err := tx.Where("user_id = ?", doc.UserID).First(&profile).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if models.IsStrongerIncomeProof(level, profile.IncomeProof) {
+			return tx.Model(&profile).Update("income_proof", level).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"ac-ai/internal/models"
+	"ac-ai/internal/money"
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// FXRateRepository - курсы из таблицы fx_rates (реализует services.FXRateProvider)
+type FXRateRepository struct {
+	db *gorm.DB
+}
+
+func NewFXRateRepository(db *gorm.DB) *FXRateRepository {
+	return &FXRateRepository{db: db}
+}
+
+func (r *FXRateRepository) RateToBase(ctx context.Context, currency money.Currency) (money.Rate, error) {
+	var row models.FXRate
+	err := r.db.WithContext(ctx).Where("currency = ?", currency).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return money.Rate{}, fmt.Errorf("%w: %s", money.ErrRateNotFound, currency)
+	}
+	if err != nil {
+		return money.Rate{}, err
+	}
+	return row.RateToBase, nil
+}
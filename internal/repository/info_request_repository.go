@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"ac-ai/internal/events"
+	"ac-ai/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInfoRequestNotOpen - запрос уже отвечен, истек или принадлежит другому клиенту
+var ErrInfoRequestNotOpen = errors.New("info request is not open")
+
+// Комментарий, который ставится заявке при истечении запроса
+const infoRequestExpiredNote = "Клиент не предоставил запрошенную информацию в срок"
+
+// RequestInfo - Вызывается агентом: вместо решения просит у клиента документ или пояснение
+// Заявка уходит из очереди (INFO_REQUESTED) до ответа клиента или истечения срока.
+// Закрепление снимается: после ответа заявка возвращается в общую очередь
+func (r *ApplicationRepository) RequestInfo(appID, agentID uint, kind, message string, expiresAt time.Time) (*models.InfoRequest, error) {
+	request := models.InfoRequest{
+		ApplicationID: appID,
+		AgentID:       agentID,
+		Kind:          kind,
+		Message:       message,
+		Status:        models.InfoRequestStatusOpen,
+		ExpiresAt:     expiresAt,
+	}
+
+	var app models.ScoringApplication
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ScoringApplication{}).
+			Where("id = ?", appID).
+			Scopes(ReviewQueueScope, claimableBy(agentID)).
+			Updates(map[string]any{
+				"agent_status":  models.AgentStatusInfoRequested,
+				"claimed_by_id": nil,
+				"claimed_at":    nil,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return r.claimConflict(appID, ErrApplicationClaimed)
+		}
+
+		if err := tx.Create(&request).Error; err != nil {
+			return err
+		}
+		return tx.First(&app, appID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.events.Publish(events.Event{
+		Type:          events.ApplicationInfoRequested,
+		ApplicationID: app.ID,
+		FinalDecision: app.FinalDecision,
+		AgentStatus:   app.AgentStatus,
+		AgentID:       &agentID,
+	})
+	return &request, nil
+}
+
+// GetOpenInfoRequest - открытый запрос, адресованный этому клиенту
+func (r *ApplicationRepository) GetOpenInfoRequest(requestID, userID uint) (*models.InfoRequest, error) {
+	var request models.InfoRequest
+	err := r.db.
+		Joins("JOIN scoring_applications ON scoring_applications.id = info_requests.application_id").
+		Where("info_requests.id = ? AND scoring_applications.user_id = ?", requestID, userID).
+		Where("info_requests.status = ? AND info_requests.expires_at > ?", models.InfoRequestStatusOpen, time.Now()).
+		First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInfoRequestNotOpen
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// GetOpenInfoRequestsForUser - Вызывается клиентом: на что нужно ответить
+func (r *ApplicationRepository) GetOpenInfoRequestsForUser(userID uint) ([]models.InfoRequest, error) {
+	var requests []models.InfoRequest
+	err := r.db.
+		Joins("JOIN scoring_applications ON scoring_applications.id = info_requests.application_id").
+		Where("scoring_applications.user_id = ?", userID).
+		Where("info_requests.status = ? AND info_requests.expires_at > ?", models.InfoRequestStatusOpen, time.Now()).
+		Order("info_requests.created_at desc").
+		Find(&requests).Error
+	return requests, err
+}
+
+// RespondToInfoRequest - Вызывается клиентом: сохраняет ответ, привязывает
+// уже загруженные вложения и возвращает заявку в очередь агента
+func (r *ApplicationRepository) RespondToInfoRequest(requestID, userID uint, text string, attachmentIDs []uint) (*models.InfoRequest, error) {
+	request, err := r.GetOpenInfoRequest(requestID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var app models.ScoringApplication
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		// 1. Закрываем запрос (повторная проверка статуса - защита от двойного ответа)
+		now := time.Now()
+		result := tx.Model(&models.InfoRequest{}).
+			Where("id = ? AND status = ?", request.ID, models.InfoRequestStatusOpen).
+			Updates(map[string]any{
+				"status":        models.InfoRequestStatusAnswered,
+				"response_text": text,
+				"responded_at":  now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInfoRequestNotOpen
+		}
+
+		// 2. Вложения (только документы этого же клиента)
+		if len(attachmentIDs) > 0 {
+			if err := tx.Model(&models.Document{}).
+				Where("id IN ? AND user_id = ?", attachmentIDs, userID).
+				Update("info_request_id", request.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// 3. Заявка снова ждет агента
+		if err := tx.Model(&models.ScoringApplication{}).
+			Where("id = ? AND agent_status = ?", request.ApplicationID, models.AgentStatusInfoRequested).
+			Update("agent_status", models.AgentStatusPending).Error; err != nil {
+			return err
+		}
+		return tx.First(&app, request.ApplicationID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.events.Publish(events.Event{
+		Type:          events.ApplicationInfoProvided,
+		ApplicationID: app.ID,
+		FinalDecision: app.FinalDecision,
+		AgentStatus:   app.AgentStatus,
+	})
+
+	if err := r.db.Preload("Attachments").First(request, request.ID).Error; err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ExpireInfoRequests - Вызывается фоновой задачей
+// Просроченные запросы закрываются, а их заявки отклоняются. Возвращает число отклоненных заявок
+func (r *ApplicationRepository) ExpireInfoRequests(now time.Time) (int, error) {
+	var expired []models.InfoRequest
+	if err := r.db.
+		Where("status = ? AND expires_at <= ?", models.InfoRequestStatusOpen, now).
+		Find(&expired).Error; err != nil {
+		return 0, err
+	}
+
+	denied := 0
+	for _, request := range expired {
+		var app models.ScoringApplication
+		decided := false
+
+		err := r.db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.InfoRequest{}).
+				Where("id = ? AND status = ?", request.ID, models.InfoRequestStatusOpen).
+				Update("status", models.InfoRequestStatusExpired)
+			if result.Error != nil || result.RowsAffected == 0 {
+				// Клиент успел ответить (или ошибка) - заявку не трогаем
+				return result.Error
+			}
+
+			result = tx.Model(&models.ScoringApplication{}).
+				Where("id = ? AND agent_status = ?", request.ApplicationID, models.AgentStatusInfoRequested).
+				Updates(map[string]any{
+					"agent_status":   models.AgentStatusDenied,
+					"agent_notes":    infoRequestExpiredNote,
+					"reviewed_by_id": request.AgentID,
+					"reviewed_at":    now,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return nil
+			}
+			decided = true
+			return tx.First(&app, request.ApplicationID).Error
+		})
+		if err != nil {
+			return denied, err
+		}
+		if !decided {
+			continue
+		}
+
+		denied++
+		r.events.Publish(events.Event{
+			Type:          events.ApplicationDecided,
+			ApplicationID: app.ID,
+			FinalDecision: app.FinalDecision,
+			AgentStatus:   app.AgentStatus,
+			AgentID:       app.ReviewedByID,
+		})
+	}
+	return denied, nil
+}
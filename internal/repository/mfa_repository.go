@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"ac-ai/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrTOTPAlreadyEnabled - MFA уже включен (например, параллельным подтверждением)
+var ErrTOTPAlreadyEnabled = errors.New("totp is already enabled")
+
+// SetPendingTOTPSecret - новый секрет при (пере)подключении. До подтверждения MFA не включен
+func (r *UserRepository) SetPendingTOTPSecret(userID uint, secret string) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ? AND totp_enabled_at IS NULL", userID).
+		Updates(map[string]any{
+			"totp_secret":    secret,
+			"totp_last_step": 0,
+		}).Error
+}
+
+// EnableTOTP - включает MFA и заменяет коды восстановления новыми.
+// Из двух параллельных подтверждений проходит одно, второе получает ErrTOTPAlreadyEnabled
+// и не перезаписывает коды, которые уже показаны пользователю
+func (r *UserRepository) EnableTOTP(userID uint, step int64, recoveryCodeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND totp_enabled_at IS NULL", userID).
+			Updates(map[string]any{
+				"totp_enabled_at": time.Now(),
+				"totp_last_step":  step,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTOTPAlreadyEnabled
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, len(recoveryCodeHashes))
+		for i, hash := range recoveryCodeHashes {
+			codes[i] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// AdvanceTOTPStep - запоминает принятый шаг. false - код с этим шагом уже использовали
+func (r *UserRepository) AdvanceTOTPStep(userID uint, step int64) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UseRecoveryCode - гасит код восстановления. false - кода нет или он уже использован
+func (r *UserRepository) UseRecoveryCode(userID uint, codeHash string) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
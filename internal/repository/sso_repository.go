@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"ac-ai/internal/models"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrOIDCStateInvalid - state не найден, уже использован или истек
+	ErrOIDCStateInvalid = errors.New("oidc login state is invalid or expired")
+	// ErrSSOAccountConflict - email из IdP занят клиентом или другой SSO-учетной записью
+	ErrSSOAccountConflict = errors.New("email belongs to an account that cannot be linked to SSO")
+)
+
+// SaveOIDCState - начало входа через IdP
+func (r *UserRepository) SaveOIDCState(stateHash, nonce, codeVerifier string, expiresAt time.Time) error {
+	return r.db.Create(&models.OIDCLoginState{
+		StateHash:    stateHash,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    expiresAt,
+	}).Error
+}
+
+// ConsumeOIDCState - забирает state (DELETE ... RETURNING): второй callback с тем же state не пройдет
+func (r *UserRepository) ConsumeOIDCState(stateHash string) (*models.OIDCLoginState, error) {
+	var state models.OIDCLoginState
+	result := r.db.Clauses(clause.Returning{}).
+		Where("state_hash = ?", stateHash).
+		Delete(&state)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 || state.ExpiresAt.Before(time.Now()) {
+		return nil, ErrOIDCStateInvalid
+	}
+
+	// Заодно чистим брошенные входы
+	r.db.Where("expires_at < ?", time.Now()).Delete(&models.OIDCLoginState{})
+	return &state, nil
+}
+
+// ProvisionSSOUser - just-in-time создание или обновление сотрудника по данным IdP.
+// Роль всегда берется из групп IdP, пароль привязанного сотрудника сбрасывается.
+// Статус DEACTIVATED не снимается: блокировка администратором важнее,
+// чем наличие учетной записи в IdP
+func (r *UserRepository) ProvisionSSOUser(subject, email, role string) (*models.User, error) {
+	var user models.User
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		locking := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+
+		err := locking.Where("oidc_subject = ?", subject).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = locking.Where("LOWER(email) = ?", strings.ToLower(email)).First(&user).Error
+		}
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			now := time.Now()
+			user = models.User{
+				Email:           email,
+				PasswordHash:    "", // Вход только через IdP
+				Role:            role,
+				Status:          models.UserStatusActive,
+				EmailVerifiedAt: &now,
+				OIDCSubject:     &subject,
+			}
+			return tx.Create(&user).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		// Клиентский аккаунт с тем же email к IdP не привязываем
+		if user.Role == models.RoleClient {
+			return ErrSSOAccountConflict
+		}
+		if user.OIDCSubject != nil && *user.OIDCSubject != subject {
+			return ErrSSOAccountConflict
+		}
+
+		// Пароль привязанного сотрудника больше не действует: вход только через IdP,
+		// иначе его блокировка в IdP не закрывала бы доступ
+		updates := map[string]any{
+			"oidc_subject":  subject,
+			"role":          role,
+			"email":         email,
+			"password_hash": "",
+		}
+		// Приглашенный сотрудник может войти через IdP, не задавая пароль
+		if user.Status == models.UserStatusInvited {
+			updates["status"] = models.UserStatusActive
+		}
+		if user.EmailVerifiedAt == nil {
+			updates["email_verified_at"] = time.Now()
+		}
+		return tx.Model(&user).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetUserByID(user.ID)
+}
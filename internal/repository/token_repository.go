@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"ac-ai/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRefreshTokenInvalid - токен не найден, истек или отозван
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid or expired")
+	// ErrRefreshTokenReused - уже использованный токен предъявлен повторно (вероятна утечка)
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+)
+
+type TokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{db: db}
+}
+
+// CreateRefreshToken - первый токен новой семьи (при логине) вместе с выданным access-токеном
+func (r *TokenRepository) CreateRefreshToken(userID uint, familyID, tokenHash string, expiresAt time.Time, accessJTI string, accessExpiresAt time.Time) error {
+	return r.db.Create(&models.RefreshToken{
+		UserID:          userID,
+		FamilyID:        familyID,
+		TokenHash:       tokenHash,
+		ExpiresAt:       expiresAt,
+		AccessJTI:       &accessJTI,
+		AccessExpiresAt: &accessExpiresAt,
+	}).Error
+}
+
+// AttachAccessToken - access-токен, выданный после ротации refresh-токена
+// (роль берется из БД уже после RotateRefreshToken)
+func (r *TokenRepository) AttachAccessToken(tokenHash, accessJTI string, accessExpiresAt time.Time) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("token_hash = ?", tokenHash).
+		Updates(map[string]any{"access_jti": accessJTI, "access_expires_at": accessExpiresAt}).Error
+}
+
+// RotateRefreshToken - меняет предъявленный токен на новый в той же семье.
+// Если токен уже был использован, отзывает всю семью и возвращает ErrRefreshTokenReused
+func (r *TokenRepository) RotateRefreshToken(tokenHash, newTokenHash string, newExpiresAt time.Time) (*models.RefreshToken, error) {
+	var current models.RefreshToken
+	var reused bool
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// FOR UPDATE: два параллельных refresh одним токеном не должны оба пройти
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", tokenHash).
+			First(&current).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRefreshTokenInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if current.RevokedAt != nil || current.ExpiresAt.Before(now) {
+			return ErrRefreshTokenInvalid
+		}
+		if current.UsedAt != nil {
+			// Отзыв семьи должен закоммититься, поэтому ошибку возвращаем уже после транзакции
+			reused = true
+			return revokeFamily(tx, current.FamilyID, now)
+		}
+
+		if err := tx.Model(&current).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.RefreshToken{
+			UserID:    current.UserID,
+			FamilyID:  current.FamilyID,
+			TokenHash: newTokenHash,
+			ExpiresAt: newExpiresAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return &current, ErrRefreshTokenReused
+	}
+	return &current, nil
+}
+
+// RevokeRefreshFamily - logout: отзывает семью, к которой относится токен
+func (r *TokenRepository) RevokeRefreshFamily(tokenHash string, userID uint) error {
+	var token models.RefreshToken
+	err := r.db.Where("token_hash = ? AND user_id = ?", tokenHash, userID).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return err
+	}
+	return revokeFamily(r.db, token.FamilyID, time.Now())
+}
+
+// RevokeUserTokens - при деактивации, смене роли или сбросе пароля: отзывает все
+// refresh-токены пользователя и еще живые access-токены, выданные в паре с ними.
+// Иначе старые права действовали бы до истечения access-токена
+func (r *TokenRepository) RevokeUserTokens(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := revokeAccessTokens(tx, "user_id = ?", userID, now); err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// RevokeAccessToken - добавляет jti в список отзыва до истечения токена
+func (r *TokenRepository) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RevokedAccessToken{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+// IsRevoked - реализует auth.RevocationList
+func (r *TokenRepository) IsRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.RevokedAccessToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
+
+// PurgeExpired - удаляет истекшие записи: отозванный jti после истечения
+// токена уже не нужен, а истекший refresh-токен отклоняется и без записи
+func (r *TokenRepository) PurgeExpired(now time.Time) (int64, error) {
+	revoked := r.db.Where("expires_at < ?", now).Delete(&models.RevokedAccessToken{})
+	if revoked.Error != nil {
+		return 0, revoked.Error
+	}
+	refresh := r.db.Where("expires_at < ?", now).Delete(&models.RefreshToken{})
+	if refresh.Error != nil {
+		return revoked.RowsAffected, refresh.Error
+	}
+	return revoked.RowsAffected + refresh.RowsAffected, nil
+}
+
+// revokeFamily - отзывает семью refresh-токенов и выданные из нее access-токены:
+// украденный refresh-токен мог уже дать злоумышленнику живой access-токен
+func revokeFamily(tx *gorm.DB, familyID string, now time.Time) error {
+	if err := revokeAccessTokens(tx, "family_id = ?", familyID, now); err != nil {
+		return err
+	}
+	return tx.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+}
+
+// revokeAccessTokens - переносит jti еще живых access-токенов из refresh_tokens в список отзыва
+func revokeAccessTokens(tx *gorm.DB, where string, arg any, now time.Time) error {
+	return tx.Exec(`INSERT INTO revoked_access_tokens (jti, expires_at, created_at)
+		SELECT access_jti, access_expires_at, ? FROM refresh_tokens
+		WHERE `+where+` AND access_jti IS NOT NULL AND access_expires_at > ?
+		ON CONFLICT (jti) DO NOTHING`, now, arg, now).Error
+}
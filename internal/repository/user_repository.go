@@ -2,11 +2,23 @@ package repository
 
 import (
 	"ac-ai/internal/models"
+	"ac-ai/internal/money"
 	"ac-ai/internal/schemas"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrInviteInvalid - приглашение не найдено, уже использовано или истекло
+	ErrInviteInvalid = errors.New("invite is invalid or expired")
+	// ErrTokenAlreadyUsed - токен из письма уже использован (email подтвержден, пароль сменен)
+	ErrTokenAlreadyUsed = errors.New("token has already been used")
 )
 
 type UserRepository struct {
@@ -22,6 +34,11 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// WithContext - копия репозитория, запросы которой идут с ctx (отмена и трассировка)
+func (r *UserRepository) WithContext(ctx context.Context) *UserRepository {
+	return &UserRepository{db: r.db.WithContext(ctx)}
+}
+
 func (r *UserRepository) GetUserByEmail(email string) (*models.User, error) {
 	var user models.User
 	// Preload("FinancialProfile") автоматически "джойнит" профиль
@@ -43,7 +60,8 @@ func (r *UserRepository) CreateUser(req *schemas.RegisterRequest, hashedPassword
 	user := models.User{
 		Email:        req.Email,
 		PasswordHash: hashedPassword,
-		Role:         req.Role,
+		Role:         models.RoleClient, // Публичная регистрация - только клиенты
+		Status:       models.UserStatusActive,
 	}
 
 	// Используем транзакцию, чтобы создать и юзера, и профиль (если надо)
@@ -66,6 +84,10 @@ func (r *UserRepository) CreateUser(req *schemas.RegisterRequest, hashedPassword
 				JobExperienceYears: req.ProfileData.JobExperienceYears,
 				Age:                req.ProfileData.Age,
 				IncomeProof:        req.ProfileData.IncomeProof,
+				Currency:           req.ProfileData.Currency,
+			}
+			if profile.Currency == "" {
+				profile.Currency = money.BaseCurrency
 			}
 			if err := tx.Create(&profile).Error; err != nil {
 				return err
@@ -76,7 +98,7 @@ func (r *UserRepository) CreateUser(req *schemas.RegisterRequest, hashedPassword
 	})
 
 	if err != nil {
-		log.Printf("Failed to create user: %v", err)
+		slog.Error("Failed to create user", slog.Any("error", err))
 		return nil, err
 	}
 
@@ -108,3 +130,174 @@ func (r *UserRepository) GetUsersByRole(role string, pagination schemas.Paginati
 		TotalItems: totalItems,
 	}, nil
 }
+
+// StreamUsersByRole - потоковая выгрузка пользователей (для экспорта клиентов)
+func (r *UserRepository) StreamUsersByRole(role string, fn func([]models.User) error) error {
+	var batch []models.User
+
+	return r.db.Model(&models.User{}).
+		Where("role = ?", role).
+		Preload("FinancialProfile").
+		FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+			return fn(batch)
+		}).Error
+}
+
+// CreateActiveStaffUser - сразу активный сотрудник (bootstrap первого администратора)
+func (r *UserRepository) CreateActiveStaffUser(email, hashedPassword, role string) (*models.User, error) {
+	user := models.User{
+		Email:        email,
+		PasswordHash: hashedPassword,
+		Role:         role,
+		Status:       models.UserStatusActive,
+	}
+	if err := r.db.Create(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CountActiveByRole - например, есть ли уже хоть один администратор
+func (r *UserRepository) CountActiveByRole(role string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).
+		Where("role = ? AND status = ?", role, models.UserStatusActive).
+		Count(&count).Error
+	return count, err
+}
+
+// InviteStaffUser - создает сотрудника без пароля и одноразовое приглашение к нему
+func (r *UserRepository) InviteStaffUser(email, role string, invitedByID uint, tokenHash string, expiresAt time.Time) (*models.User, error) {
+	user := models.User{
+		Email: email,
+		Role:  role,
+		// Пустой хэш не совпадет ни с одним паролем, войти до принятия приглашения нельзя
+		PasswordHash: "",
+		Status:       models.UserStatusInvited,
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.StaffInvite{
+			UserID:      user.ID,
+			InvitedByID: invitedByID,
+			TokenHash:   tokenHash,
+			ExpiresAt:   expiresAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// AcceptStaffInvite - сотрудник задает пароль по приглашению, учетная запись активируется
+func (r *UserRepository) AcceptStaffInvite(tokenHash, hashedPassword string) (*models.User, error) {
+	var invite models.StaffInvite
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).
+			First(&invite).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInviteInvalid
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&invite).Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).
+			Where("id = ? AND status = ?", invite.UserID, models.UserStatusInvited).
+			Updates(map[string]any{
+				"password_hash": hashedPassword,
+				"status":        models.UserStatusActive,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetUserByID(invite.UserID)
+}
+
+// GetStaffUsers - сотрудники банка (все роли, кроме CLIENT)
+func (r *UserRepository) GetStaffUsers(pagination schemas.PaginationQuery) (*PaginatedUsersResult, error) {
+	var users []models.User
+	var totalItems int64
+
+	baseQuery := r.db.Model(&models.User{}).Where("role IN ?", models.StaffRoles)
+
+	if err := baseQuery.Count(&totalItems).Error; err != nil {
+		return nil, err
+	}
+
+	err := baseQuery.
+		Order("created_at desc").
+		Scopes(PaginateScope(pagination.Page, pagination.Limit)).
+		Find(&users).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaginatedUsersResult{
+		Users:      users,
+		TotalItems: totalItems,
+	}, nil
+}
+
+// UpdateStaffUser - смена статуса или роли сотрудника. Клиентов не затрагивает
+func (r *UserRepository) UpdateStaffUser(userID uint, updates map[string]any) (*models.User, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND role IN ?", userID, models.StaffRoles).
+		Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.GetUserByID(userID)
+}
+
+// MarkEmailVerified - подтверждение email. Условие по email защищает от токена,
+// выпущенного до смены адреса, а условие по email_verified_at - от повторного использования
+func (r *UserRepository) MarkEmailVerified(userID uint, email string) error {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND email = ? AND email_verified_at IS NULL", userID, email).
+		Update("email_verified_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTokenAlreadyUsed
+	}
+	return nil
+}
+
+// ResetPassword - смена пароля по токену из письма. Сравнение со старым хэшем
+// не дает двум параллельным запросам с одним токеном сменить пароль дважды
+func (r *UserRepository) ResetPassword(userID uint, oldHash, newHash string) error {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND password_hash = ? AND status = ?", userID, oldHash, models.UserStatusActive).
+		Update("password_hash", newHash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTokenAlreadyUsed
+	}
+	return nil
+}
+
+// RehashPassword - пересчет хэша при входе после смены алгоритма или cost.
+// Если пароль успели сменить параллельно, новый хэш не перезаписывается
+func (r *UserRepository) RehashPassword(userID uint, oldHash, newHash string) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ? AND password_hash = ?", userID, oldHash).
+		Update("password_hash", newHash).Error
+}
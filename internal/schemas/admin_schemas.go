@@ -0,0 +1,59 @@
+package schemas
+
+import "time"
+
+// StaffInviteRequest - приглашение сотрудника администратором
+type StaffInviteRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=AGENT SUPERVISOR ADMIN"`
+}
+
+// StaffRoleRequest - смена роли сотрудника
+type StaffRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=AGENT SUPERVISOR ADMIN"`
+}
+
+// UnlockLoginRequest - снятие блокировки входа. IP - необязательно,
+// если заблокирован еще и адрес, с которого заходил пользователь
+type UnlockLoginRequest struct {
+	IP string `json:"ip" binding:"omitempty,ip"`
+}
+
+type StaffUserOut struct {
+	ID        uint      `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Status    string    `json:"status"`
+}
+
+// StaffInviteOut - токен показывается один раз, в БД хранится только его хэш
+type StaffInviteOut struct {
+	User        StaffUserOut `json:"user"`
+	InviteToken string       `json:"invite_token"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+}
+
+// APIKeyCreateRequest - ключ для партнера. rate_limit - запросов в минуту (0 - значение по умолчанию)
+type APIKeyCreateRequest struct {
+	Name      string   `json:"name" binding:"required,max=100"`
+	Scopes    []string `json:"scopes" binding:"required,min=1,dive,oneof=partner:scoring"`
+	RateLimit int      `json:"rate_limit" binding:"omitempty,gte=1,lte=10000"`
+}
+
+type APIKeyOut struct {
+	ID         uint       `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	RateLimit  int        `json:"rate_limit"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// APIKeyCreatedOut - полный ключ показывается один раз, в БД хранится только его хэш
+type APIKeyCreatedOut struct {
+	APIKeyOut
+	Key string `json:"key"`
+}
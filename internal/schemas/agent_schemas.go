@@ -1,6 +1,7 @@
 package schemas
 
 import (
+	"ac-ai/internal/money"
 	"time"
 )
 
@@ -15,13 +16,20 @@ type ApplicationOut struct {
 	ID              uint               `json:"id"`
 	CreatedAt       time.Time          `json:"created_at"`
 	User            ApplicationUserOut `json:"user"` // Вложенный пользователь
-	RequestedAmount float64            `json:"requested_amount"`
-	FinalDecision   string             `json:"final_decision"` // Решение ИИ
-	ColdScore       int                `json:"cold_score"`
-	AIResponse      string             `json:"ai_response"`  // Что увидел клиент
-	AgentStatus     string             `json:"agent_status"` // Статус от агента
-	AgentNotes      string             `json:"agent_notes"`
-	InternalReasons []string           `json:"internal_reasons"`
+	RequestedAmount money.Amount       `json:"requested_amount"`
+	Currency        money.Currency     `json:"currency"`
+	// Сумма в базовой валюте по курсу на момент заявки
+	RequestedAmountBase money.Amount `json:"requested_amount_base"`
+	FinalDecision       string       `json:"final_decision"` // Решение ИИ
+	ColdScore           int          `json:"cold_score"`
+	AIResponse          string       `json:"ai_response"`  // Что увидел клиент
+	AgentStatus         string       `json:"agent_status"` // Статус от агента
+	AgentNotes          string       `json:"agent_notes"`
+	// Агент, взявший заявку в работу, и когда (пусто - свободна)
+	ClaimedByID     *uint            `json:"claimed_by_id,omitempty"`
+	ClaimedAt       *time.Time       `json:"claimed_at,omitempty"`
+	InternalReasons []string         `json:"internal_reasons"`
+	InfoRequests    []InfoRequestOut `json:"info_requests,omitempty"` // Переписка с клиентом
 }
 
 // Профиль клиента для просмотра агентом
@@ -52,3 +60,26 @@ type PaginatedResponse struct {
 	Data any            `json:"data"` // Здесь будут лежать наши заявки или клиенты
 	Meta PaginationMeta `json:"meta"`
 }
+
+// Представления заявок: те же выборки, что и в списках агента
+const (
+	ApplicationViewAll    = "all"    // как /agent/applications/all
+	ApplicationViewReview = "review" // как /agent/applications/review
+)
+
+// ExportQuery - параметры выгрузки (?format=csv|xlsx)
+type ExportQuery struct {
+	Format string `form:"format,default=csv" binding:"oneof=csv xlsx"`
+}
+
+// ApplicationExportQuery - выгрузка заявок с тем же фильтром, что и в списках
+type ApplicationExportQuery struct {
+	ExportQuery
+	View string `form:"view,default=all" binding:"oneof=all review"`
+}
+
+// AgentDecisionRequest - ручное решение агента по заявке из очереди
+type AgentDecisionRequest struct {
+	Status string `json:"status" binding:"required,oneof=AGENT_APPROVED AGENT_DENIED"`
+	Notes  string `json:"notes" binding:"max=2000"`
+}
@@ -1,21 +1,27 @@
 package schemas
 
+import (
+	"ac-ai/internal/money"
+	"time"
+)
+
 // В Go мы используем struct tags для валидации JSON
 
 type FinancialProfileCreate struct {
-	Income             float64 `json:"income" binding:"required,gte=0"`
-	MonthlyPayments    float64 `json:"monthly_payments" binding:"required,gte=0"`
-	CreditHistory      string  `json:"credit_history" binding:"required,oneof=no_issues minor_issues major_issues"`
-	JobExperienceYears float64 `json:"job_experience_years" binding:"required,gte=0"`
-	Age                int     `json:"age" binding:"required,gte=18"`
-	IncomeProof        string  `json:"income_proof" binding:"required,oneof=official indirect verbal"`
+	Income             money.Amount   `json:"income" binding:"required,gte=0"`
+	MonthlyPayments    money.Amount   `json:"monthly_payments" binding:"required,gte=0"`
+	Currency           money.Currency `json:"currency" binding:"omitempty,currency"` // Валюта дохода, по умолчанию KZT
+	CreditHistory      string         `json:"credit_history" binding:"required,oneof=no_issues minor_issues major_issues"`
+	JobExperienceYears float64        `json:"job_experience_years" binding:"required,gte=0"`
+	Age                int            `json:"age" binding:"required,gte=18"`
+	IncomeProof        string         `json:"income_proof" binding:"required,oneof=official indirect verbal"`
 }
 
 type RegisterRequest struct {
 	Email       string                  `json:"email" binding:"required,email"`
-	Password    string                  `json:"password" binding:"required,min=6"`
-	Role        string                  `json:"role" binding:"required,oneof=CLIENT AGENT"`
-	ProfileData *FinancialProfileCreate `json:"profile_data,omitempty"` // omitempty, т.к. для AGENT его нет
+	Password    string                  `json:"password" binding:"required,password"`  // Парольная политика: длина и список утекших паролей
+	Role        string                  `json:"role" binding:"omitempty,oneof=CLIENT"` // Сотрудников создает только администратор
+	ProfileData *FinancialProfileCreate `json:"profile_data,omitempty"`                // omitempty, т.к. для AGENT его нет
 }
 
 type LoginRequest struct {
@@ -24,8 +30,77 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Время жизни access-токена в секундах
+	RefreshToken string `json:"refresh_token"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest - refresh_token необязателен: без него отзывается только текущий access-токен
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// AcceptInviteRequest - сотрудник задает пароль по ссылке-приглашению
+type AcceptInviteRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,password"`
+}
+
+// UserOut - созданная учетная запись (ответ на регистрацию)
+type UserOut struct {
+	ID              uint       `json:"id"`
+	CreatedAt       time.Time  `json:"created_at"`
+	Email           string     `json:"email"`
+	Role            string     `json:"role"`
+	Status          string     `json:"status"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
+
+// MFAChallengeResponse - пароль верный, но для входа нужен второй фактор
+type MFAChallengeResponse struct {
+	MFARequired           bool   `json:"mfa_required"`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required"` // Сначала подключить TOTP (/auth/mfa/totp/enroll)
+	MFAToken              string `json:"mfa_token"`
+	ExpiresIn             int    `json:"expires_in"`
+}
+
+// MFAVerifyRequest - второй шаг входа: TOTP-код или код восстановления
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode,omitempty,len=6,numeric"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TOTPEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"` // Для QR-кода
+}
+
+type TOTPConfirmRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric"`
+}
+
+// TOTPConfirmResponse - коды восстановления показываются один раз.
+// Tokens заполнен, если подключение было частью входа (mfa_enrollment_required)
+type TOTPConfirmResponse struct {
+	RecoveryCodes []string       `json:"recovery_codes"`
+	Tokens        *LoginResponse `json:"tokens,omitempty"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
 }
 
-// ... (Добавьте UserOut, ProfileOut, если нужно)
\ No newline at end of file
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,password"`
+}
@@ -0,0 +1,27 @@
+package schemas
+
+import "time"
+
+// DocumentUploadForm - поля multipart-формы (сам файл - в поле "file")
+type DocumentUploadForm struct {
+	Kind string `form:"kind" binding:"required,oneof=salary_certificate bank_statement"`
+}
+
+// DocumentReviewRequest - решение агента по документу
+type DocumentReviewRequest struct {
+	Status string `json:"status" binding:"required,oneof=VERIFIED REJECTED"`
+	Notes  string `json:"notes" binding:"max=2000"`
+}
+
+type DocumentOut struct {
+	ID          uint       `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UserID      uint       `json:"user_id"`
+	Kind        string     `json:"kind"`
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	Status      string     `json:"status"`
+	ReviewNotes string     `json:"review_notes,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
+}
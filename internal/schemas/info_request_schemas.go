@@ -0,0 +1,27 @@
+package schemas
+
+import "time"
+
+// InfoRequestCreate - агент просит у клиента документ или пояснение
+type InfoRequestCreate struct {
+	Kind    string `json:"kind" binding:"required,oneof=document clarification"`
+	Message string `json:"message" binding:"required,min=5,max=2000"`
+}
+
+// InfoResponseForm - ответ клиента (multipart: text и файлы в поле "attachments")
+type InfoResponseForm struct {
+	Text string `form:"text" binding:"required,max=4000"`
+}
+
+type InfoRequestOut struct {
+	ID            uint          `json:"id"`
+	ApplicationID uint          `json:"application_id"`
+	CreatedAt     time.Time     `json:"created_at"`
+	Kind          string        `json:"kind"`
+	Message       string        `json:"message"`
+	Status        string        `json:"status"`
+	ExpiresAt     time.Time     `json:"expires_at"`
+	ResponseText  string        `json:"response_text,omitempty"`
+	RespondedAt   *time.Time    `json:"responded_at,omitempty"`
+	Attachments   []DocumentOut `json:"attachments,omitempty"`
+}
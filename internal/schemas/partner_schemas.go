@@ -0,0 +1,22 @@
+package schemas
+
+import "ac-ai/internal/money"
+
+// PartnerScoringRequest - предварительный скоринг от партнера: профиль приходит в запросе,
+// клиент в нашей системе не регистрируется
+type PartnerScoringRequest struct {
+	ExternalID       string                 `json:"external_id" binding:"omitempty,max=64"` // ID заявки у партнера (для сверки)
+	RequestedAmount  money.Amount           `json:"requested_amount" binding:"required,gt=0"`
+	Currency         money.Currency         `json:"currency" binding:"omitempty,currency"` // Валюта кредита, по умолчанию KZT
+	FinancialProfile FinancialProfileCreate `json:"financial_profile" binding:"required"`
+}
+
+type PartnerScoringResponse struct {
+	ExternalID           string         `json:"external_id,omitempty"`
+	Decision             string         `json:"decision"` // APPROVED, DENIED, MANUAL_REVIEW
+	Score                int            `json:"score"`
+	DtiRatio             float64        `json:"dti_ratio"`
+	RecommendedMaxAmount money.Amount   `json:"recommended_max_amount"`
+	Currency             money.Currency `json:"currency"`
+	Reasons              []string       `json:"reasons"`
+}
@@ -1,7 +1,11 @@
 package schemas
 
+import "ac-ai/internal/money"
+
 type ScoringRequest struct {
 	Query string `json:"query" binding:"required,min=5"`
+	// Валюта кредита, если клиент выбрал ее в интерфейсе; иначе берется из текста запроса
+	Currency money.Currency `json:"currency" binding:"omitempty,currency"`
 }
 
 type ScoringResponse struct {
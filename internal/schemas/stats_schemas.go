@@ -0,0 +1,44 @@
+package schemas
+
+import (
+	"ac-ai/internal/money"
+	"time"
+)
+
+// StatsQuery - период статистики (?from=2025-01-01&to=2025-01-31), обе даты включительно
+type StatsQuery struct {
+	From time.Time `form:"from" time_format:"2006-01-02"`
+	To   time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+// QueueAgeOut - возраст заявок в очереди ручного рассмотрения (в секундах)
+type QueueAgeOut struct {
+	Size int64   `json:"size"`
+	P50  float64 `json:"p50_seconds"`
+	P90  float64 `json:"p90_seconds"`
+	P99  float64 `json:"p99_seconds"`
+}
+
+// AgentThroughputOut - решения одного агента за период
+type AgentThroughputOut struct {
+	AgentID  uint   `json:"agent_id"`
+	Email    string `json:"email"`
+	Decided  int64  `json:"decided"`
+	Approved int64  `json:"approved"`
+	Denied   int64  `json:"denied"`
+}
+
+// StatsOut - дашборд статистики агента
+type StatsOut struct {
+	From                    time.Time            `json:"from"`
+	To                      time.Time            `json:"to"`
+	TotalApplications       int64                `json:"total_applications"`
+	ByFinalDecision         map[string]int64     `json:"by_final_decision"`
+	ByAgentStatus           map[string]int64     `json:"by_agent_status"`
+	ApprovalRate            float64              `json:"approval_rate"`
+	AvgColdScore            float64              `json:"avg_cold_score"`
+	AvgRequestedAmount      money.Amount         `json:"avg_requested_amount"`
+	AvgRecommendedMaxAmount money.Amount         `json:"avg_recommended_max_amount"`
+	ReviewQueueAge          QueueAgeOut          `json:"review_queue_age"`
+	AgentThroughput         []AgentThroughputOut `json:"agent_throughput"`
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"ac-ai/internal/auth"
+	"ac-ai/internal/mail"
+	"ac-ai/internal/models"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AccountEmailService - письма со ссылками подтверждения email и сброса пароля
+type AccountEmailService struct {
+	jwt       *auth.JWTService
+	mailer    mail.Mailer
+	baseURL   string // Адрес фронтенда, на котором открываются ссылки из писем
+	verifyTTL time.Duration
+	resetTTL  time.Duration
+}
+
+func NewAccountEmailService(
+	jwt *auth.JWTService,
+	mailer mail.Mailer,
+	baseURL string,
+	verifyTTL, resetTTL time.Duration,
+) *AccountEmailService {
+	return &AccountEmailService{
+		jwt:       jwt,
+		mailer:    mailer,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		verifyTTL: verifyTTL,
+		resetTTL:  resetTTL,
+	}
+}
+
+// SendVerification - ссылка подтверждения email (после регистрации или по запросу)
+func (s *AccountEmailService) SendVerification(ctx context.Context, user *models.User) error {
+	token, err := s.jwt.CreateActionToken(user.ID, auth.PurposeEmailVerify, user.Email, s.verifyTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/verify-email", token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf(
+			"Please confirm your email address by opening the link below:\n\n%s\n\nThe link is valid for %s.\n",
+			link, formatTTL(s.verifyTTL),
+		),
+	})
+}
+
+// SendPasswordReset - ссылка сброса пароля. Токен привязан к текущему хэшу пароля,
+// поэтому после смены пароля все ранее отправленные ссылки перестают работать.
+// То же происходит при пересчете хэша во время входа (смена параметров хэширования)
+func (s *AccountEmailService) SendPasswordReset(ctx context.Context, user *models.User) error {
+	token, err := s.jwt.CreateActionToken(user.ID, auth.PurposePasswordReset, user.PasswordHash, s.resetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/reset-password", token)
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"We received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\n"+
+				"The link is valid for %s. If you did not request a reset, ignore this email.\n",
+			link, formatTTL(s.resetTTL),
+		),
+	})
+}
+
+func (s *AccountEmailService) link(path, token string) string {
+	return s.baseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func formatTTL(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d minutes", int(d.Minutes()))
+}
@@ -2,66 +2,144 @@ package services
 
 import (
 	"ac-ai/internal/config"
+	"ac-ai/internal/metrics"
 	"ac-ai/internal/models"
+	"ac-ai/internal/money"
+	"ac-ai/internal/tracing"
 	"context"
 	"encoding/json"
-	"log"
-	"strconv"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"strings"
+	"time"
 
 	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type AIService struct {
 	client *openai.Client
+	// Промпты содержат финансовый профиль клиента - в спаны только по явному флагу
+	capturePrompts bool
 }
 
 func NewAIService(cfg *config.Config) *AIService {
+	clientCfg := openai.DefaultConfig(cfg.OpenAIAPIKey)
+	clientCfg.HTTPClient = &http.Client{Transport: &tracing.Transport{}}
 	return &AIService{
-		client: openai.NewClient(cfg.OpenAIAPIKey),
+		client:         openai.NewClientWithConfig(clientCfg),
+		capturePrompts: cfg.TracingCapturePrompts,
 	}
 }
 
-// 1. Извлечение суммы (без изменений)
-func (s *AIService) ParseAmountFromQuery(ctx context.Context, query string) (float64, error) {
-	resp, err := s.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: "gpt-4o-mini",
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: "Ты - парсер. Извлеки число (сумму) из запроса. Ответь ТОЛЬКО числом (например '15000000'). Если числа нет, ответь '0'.",
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: query,
-				},
+// chat - вызов модели с метриками латентности, ошибок и токенов и спаном трассы
+func (s *AIService) chat(ctx context.Context, operation string, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "chat "+req.Model,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("gen_ai.system", "openai"),
+			attribute.String("gen_ai.operation.name", "chat"),
+			attribute.String("gen_ai.request.model", req.Model),
+			attribute.Float64("gen_ai.request.temperature", float64(req.Temperature)),
+			attribute.String("app.ai.operation", operation),
+		),
+	)
+	defer span.End()
+	if s.capturePrompts {
+		for i, msg := range req.Messages {
+			span.SetAttributes(attribute.String(fmt.Sprintf("gen_ai.prompt.%d.%s", i, msg.Role), msg.Content))
+		}
+	}
+
+	started := time.Now()
+	resp, err := s.client.CreateChatCompletion(ctx, req)
+	metrics.ObserveAI(req.Model, operation, started, err, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+
+	span.SetAttributes(
+		attribute.String("gen_ai.response.model", resp.Model),
+		attribute.Int("gen_ai.usage.input_tokens", resp.Usage.PromptTokens),
+		attribute.Int("gen_ai.usage.output_tokens", resp.Usage.CompletionTokens),
+	)
+	if s.capturePrompts && len(resp.Choices) > 0 {
+		span.SetAttributes(attribute.String("gen_ai.completion.0.content", resp.Choices[0].Message.Content))
+	}
+	return resp, nil
+}
+
+// Ping - доступность провайдера ИИ и валидность ключа (для /readyz)
+func (s *AIService) Ping(ctx context.Context) error {
+	_, err := s.client.ListModels(ctx)
+	return err
+}
+
+// 1. Извлечение суммы и валюты. Если валюта в запросе не названа, Currency пустая -
+// ее выбирает вызывающий код
+func (s *AIService) ParseAmountFromQuery(ctx context.Context, query string) (money.Money, error) {
+	resp, err := s.chat(ctx, "parse_amount", openai.ChatCompletionRequest{
+		Model: "gpt-4o-mini",
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: "Ты - парсер. Извлеки сумму и валюту из запроса. Ответь ТОЛЬКО числом и кодом валюты ISO 4217 через пробел (например '15000000 KZT' или '20000 USD'). Если валюта не названа, ответь только числом. Если числа нет, ответь '0'.",
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: query,
 			},
-			Temperature: 0,
 		},
-	)
+		Temperature: 0,
+	})
 
 	if err != nil {
-		return 0, err
+		return money.Money{}, err
+	}
+
+	amountStr := strings.TrimSpace(resp.Choices[0].Message.Content)
+
+	// Код валюты - последние три буквы ответа
+	var currency money.Currency
+	if n := len(amountStr); n >= 3 && isLetters(amountStr[n-3:]) {
+		currency, err = money.ParseCurrency(amountStr[n-3:])
+		if err != nil {
+			return money.Money{}, err
+		}
+		amountStr = amountStr[:n-3]
 	}
 
-	amountStr := resp.Choices[0].Message.Content
 	amountStr = strings.ReplaceAll(amountStr, " ", "")
 	// ** Исправляем парсинг для больших чисел **
 	amountStr = strings.ReplaceAll(amountStr, ",", "")
-	amount, err := strconv.ParseFloat(amountStr, 64)
+	amount, err := money.Parse(amountStr)
 	if err != nil {
-		return 0, err
+		return money.Money{}, err
 	}
 
-	return amount, nil
+	return money.New(amount, currency), nil
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
 }
 
 // 2. "Теплый" анализ (ИСПРАВЛЕННЫЙ ПРОМПТ)
 func (s *AIService) GetAIAnalysis(ctx context.Context, scoreData *ColdScoreResult, profile *models.FinancialProfile) (string, error) {
 
 	scoreDataBytes, _ := json.MarshalIndent(scoreData, "", "  ")
+	requested := money.New(scoreData.RequestedAmount, scoreData.Currency).Format()
+	recommended := money.New(scoreData.RecommendedMaxAmount, scoreData.Currency).Format()
 
 	systemPrompt := `
 	Ты - AI-ассистент банка, вежливый и профессиональный кредитный аналитик.
@@ -80,20 +158,20 @@ func (s *AIService) GetAIAnalysis(ctx context.Context, scoreData *ColdScoreResul
 
 	2.  **Если 'decision' == "APPROVED":**
 		* Поздравь клиента.
-		* Сообщи, что заявка на ` + strconv.FormatFloat(scoreData.RequestedAmount, 'f', 0, 64) + ` тг предварительно одобрена.
+		* Сообщи, что заявка на ` + requested + ` предварительно одобрена.
 
 	3.  **Если 'decision' == "MANUAL_REVIEW":**
-		* Сообщи, что заявка на ` + strconv.FormatFloat(scoreData.RequestedAmount, 'f', 0, 64) + ` тг отправлена на ручное рассмотрение.
+		* Сообщи, что заявка на ` + requested + ` отправлена на ручное рассмотрение.
 		* **Объясни причину:** Посмотри на 'recommendations'. Вежливо перечисли 1-2 основные причины (например, "из-за недавних просрочек" или "из-за высокого стажа").
 		* **Проверь сумму:** Если 'requestedAmount' > 'recommendedMaxAmount', обязательно добавь: "В частности, запрошенная вами сумма может быть слишком высокой для вашего текущего дохода. Возможно, наш менеджер предложит вам скорректированную сумму."
 
 	4.  **Если 'decision' == "DENIED":**
-		* Вежливо сообщи об отказе по заявке на ` + strconv.FormatFloat(scoreData.RequestedAmount, 'f', 0, 64) + ` тг.
+		* Вежливо сообщи об отказе по заявке на ` + requested + `.
 		* **ОБЯЗАТЕЛЬНО объясни главную причину:**
 			* **Сценарий 1: Сумма слишком велика (ЭТО ГЛАВНЫЙ СЦЕНАРИЙ ДЛЯ 50 МЛРД).**
 				* Проверь, если 'requestedAmount' > 'recommendedMaxAmount'.
-				* Если это так, скажи: "К сожалению, в кредите отказано. Основная причина - запрошенная сумма ( ` + strconv.FormatFloat(scoreData.RequestedAmount, 'f', 0, 64) + ` тг) слишком велика для вашего текущего уровня подтвержденного дохода."
-				* **!!ДАЙ АЛЬТЕРНАТИВУ!!:** "На основе вашего профиля, мы могли бы быстро одобрить вам сумму в размере **` + strconv.FormatFloat(scoreData.RecommendedMaxAmount, 'f', 0, 64) + ` тг**. Вы можете подать повторную заявку на эту сумму."
+				* Если это так, скажи: "К сожалению, в кредите отказано. Основная причина - запрошенная сумма ( ` + requested + `) слишком велика для вашего текущего уровня подтвержденного дохода."
+				* **!!ДАЙ АЛЬТЕРНАТИВУ!!:** "На основе вашего профиля, мы могли бы быстро одобрить вам сумму в размере **` + recommended + `**. Вы можете подать повторную заявку на эту сумму."
 			* **Сценарий 2: Плохая кредитная история или другие факторы (сумма в порядке).**
 				* Если 'requestedAmount' <= 'recommendedMaxAmount' (т.е. дело не в сумме), посмотри на 'recommendations'.
 				* Скажи: "К сожалению, в кредите отказано. Основные причины: " (и перечисли 1-2 пункта из 'recommendations', например, "наличие серьезных просрочек в кредитной истории" или "высокая текущая долговая нагрузка").
@@ -102,26 +180,23 @@ func (s *AIService) GetAIAnalysis(ctx context.Context, scoreData *ColdScoreResul
 	Используй вежливый и заботливый тон.
 	`
 
-	resp, err := s.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: "gpt-4o-mini",
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: systemPrompt,
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: "Сформулируй ответ для клиента.",
-				},
+	resp, err := s.chat(ctx, "analysis", openai.ChatCompletionRequest{
+		Model: "gpt-4o-mini",
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: systemPrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: "Сформулируй ответ для клиента.",
 			},
-			Temperature: 0.7,
 		},
-	)
+		Temperature: 0.7,
+	})
 
 	if err != nil {
-		log.Printf("OpenAI API error: %v", err)
+		slog.ErrorContext(ctx, "OpenAI API error", slog.Any("error", err))
 		return "", err
 	}
 
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"ac-ai/internal/api/middleware"
+	"ac-ai/internal/models"
+	"ac-ai/internal/tracing"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	openai "github.com/sashabaranov/go-openai"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Запрос к API порождает дерево спанов HTTP -> GORM и HTTP -> вызов модели,
+// а traceparent уходит дальше в запрос к OpenAI
+func TestTraceSpansHTTPToGormAndAI(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := tracing.Setup(tracing.Options{ServiceName: "ac-ai-test", SampleRatio: 1, Exporter: exporter})
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	var aiTraceparent string
+	openaiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		aiTraceparent = r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Model:   "gpt-4o-mini",
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: "assistant", Content: "20000 USD"}}},
+			Usage:   openai.Usage{PromptTokens: 12, CompletionTokens: 3},
+		})
+	}))
+	defer openaiServer.Close()
+
+	clientCfg := openai.DefaultConfig("test-key")
+	clientCfg.BaseURL = openaiServer.URL + "/v1"
+	clientCfg.HTTPClient = &http.Client{Transport: &tracing.Transport{}}
+	ai := &AIService{client: openai.NewClientWithConfig(clientCfg)}
+
+	// DryRun: GORM строит SQL и проходит колбэки, но в БД не ходит
+	db, err := gorm.Open(postgres.Open("host=localhost dbname=test"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.TracingMiddleware())
+	r.GET("/clients/:id", func(c *gin.Context) {
+		ctx := c.Request.Context()
+		var user models.User
+		db.WithContext(ctx).Where("id = ?", c.Param("id")).Find(&user)
+		if _, err := ai.ParseAmountFromQuery(ctx, "20 тысяч долларов"); err != nil {
+			c.AbortWithStatus(http.StatusBadGateway)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	const parentTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/clients/42", nil)
+	req.Header.Set("traceparent", "00-"+parentTraceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	if err := tp.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	spans := exporter.GetSpans()
+
+	find := func(name string) tracetest.SpanStub {
+		t.Helper()
+		for _, s := range spans {
+			if s.Name == name {
+				return s
+			}
+		}
+		t.Fatalf("span %q not found among %d spans", name, len(spans))
+		return tracetest.SpanStub{}
+	}
+	server := find("GET /clients/:id")
+	query := find("db.select users")
+	chat := find("chat gpt-4o-mini")
+
+	if server.SpanKind != trace.SpanKindServer || query.SpanKind != trace.SpanKindClient || chat.SpanKind != trace.SpanKindClient {
+		t.Errorf("unexpected span kinds: server=%v db=%v chat=%v", server.SpanKind, query.SpanKind, chat.SpanKind)
+	}
+	// Входящий traceparent продолжается, а не начинает новую трассу
+	if got := server.SpanContext.TraceID().String(); got != parentTraceID {
+		t.Errorf("server trace id = %s, want %s", got, parentTraceID)
+	}
+	if !server.Parent.IsRemote() {
+		t.Error("server span should have the remote caller as parent")
+	}
+	for _, child := range []tracetest.SpanStub{query, chat} {
+		if child.Parent.SpanID() != server.SpanContext.SpanID() {
+			t.Errorf("span %q parent = %s, want server span %s", child.Name, child.Parent.SpanID(), server.SpanContext.SpanID())
+		}
+		if child.SpanContext.TraceID() != server.SpanContext.TraceID() {
+			t.Errorf("span %q is in another trace", child.Name)
+		}
+	}
+
+	// SQL без значений параметров, промпты без TRACING_CAPTURE_PROMPTS не пишутся
+	attrs := func(s tracetest.SpanStub) map[string]string {
+		m := map[string]string{}
+		for _, kv := range s.Attributes {
+			m[string(kv.Key)] = kv.Value.Emit()
+		}
+		return m
+	}
+	if stmt := attrs(query)["db.statement"]; !strings.Contains(stmt, "$1") || strings.Contains(stmt, "42") {
+		t.Errorf("db.statement = %q, want placeholders without values", stmt)
+	}
+	chatAttrs := attrs(chat)
+	if chatAttrs["gen_ai.usage.input_tokens"] != "12" || chatAttrs["app.ai.operation"] != "parse_amount" {
+		t.Errorf("unexpected chat attributes: %v", chatAttrs)
+	}
+	for key := range chatAttrs {
+		if strings.HasPrefix(key, "gen_ai.prompt.") {
+			t.Errorf("prompt captured without TRACING_CAPTURE_PROMPTS: %s", key)
+		}
+	}
+
+	// Запрос к OpenAI несет traceparent спана вызова модели
+	want := "00-" + parentTraceID + "-" + chat.SpanContext.SpanID().String() + "-01"
+	if aiTraceparent != want {
+		t.Errorf("traceparent sent to OpenAI = %q, want %q", aiTraceparent, want)
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"ac-ai/internal/models"
+	"ac-ai/internal/repository"
+	"ac-ai/internal/storage"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrDocumentTooLarge        = errors.New("document exceeds maximum upload size")
+	ErrUnsupportedDocumentType = errors.New("unsupported document type")
+)
+
+// Длина documents.filename (varchar(255))
+const maxFilenameBytes = 255
+
+// Разрешенные типы (определяются по содержимому, а не по расширению) -> расширение файла
+var allowedDocumentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+type DocumentService struct {
+	repo    *repository.DocumentRepository
+	store   storage.BlobStore
+	scanner storage.VirusScanner
+	maxSize int64
+}
+
+func NewDocumentService(
+	repo *repository.DocumentRepository,
+	store storage.BlobStore,
+	scanner storage.VirusScanner,
+	maxSize int64,
+) *DocumentService {
+	return &DocumentService{
+		repo:    repo,
+		store:   store,
+		scanner: scanner,
+		maxSize: maxSize,
+	}
+}
+
+// MaxSize - лимит размера файла в байтах
+func (s *DocumentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// cleanFilename - имя файла без пути, в валидном UTF-8 и не длиннее колонки.
+// Обрезается по границе символа, чтобы не резать многобайтную кириллицу пополам
+func cleanFilename(filename string) string {
+	name := strings.ToValidUTF8(filepath.Base(filename), "\uFFFD")
+	if len(name) <= maxFilenameBytes {
+		return name
+	}
+	cut := maxFilenameBytes
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+	return name[:cut]
+}
+
+// Upload - проверяет и сохраняет документ клиента.
+// Зараженный файл не сохраняется, но запись со статусом INFECTED остается для аудита
+func (s *DocumentService) Upload(ctx context.Context, userID uint, kind, filename string, r io.Reader) (*models.Document, error) {
+	// 1. Читаем не больше лимита (+1 байт, чтобы отличить "ровно лимит" от "больше")
+	data, err := io.ReadAll(io.LimitReader(r, s.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > s.maxSize {
+		return nil, ErrDocumentTooLarge
+	}
+
+	// 2. MIME по сигнатуре файла
+	contentType := http.DetectContentType(data)
+	ext, ok := allowedDocumentTypes[contentType]
+	if !ok {
+		return nil, ErrUnsupportedDocumentType
+	}
+
+	doc := models.Document{
+		UserID:      userID,
+		Kind:        kind,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Status:      models.DocumentStatusPendingReview,
+	}
+
+	// 3. Антивирус
+	if err := s.scanner.Scan(ctx, bytes.NewReader(data)); err != nil {
+		if !errors.Is(err, storage.ErrInfected) {
+			return nil, fmt.Errorf("virus scan failed: %w", err)
+		}
+		doc.Status = models.DocumentStatusInfected
+		if err := s.repo.CreateDocument(&doc); err != nil {
+			return nil, err
+		}
+		return &doc, storage.ErrInfected
+	}
+
+	// 4. Сохраняем файл, затем запись в БД
+	key, err := newStorageKey(userID, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	doc.StorageKey = key
+
+	if err := s.repo.CreateDocument(&doc); err != nil {
+		// Не оставляем "осиротевший" файл без записи
+		if delErr := s.store.Delete(ctx, key); delErr != nil {
+			slog.ErrorContext(ctx, "Failed to clean up blob", slog.String("key", key), slog.Any("error", delErr))
+		}
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// Discard - откатывает Upload: удаляет файл и запись. Нужен, когда документ
+// загружался как часть другой операции (ответ на запрос агента), а она не удалась
+func (s *DocumentService) Discard(ctx context.Context, doc *models.Document) error {
+	if doc.StorageKey != "" {
+		if err := s.store.Delete(ctx, doc.StorageKey); err != nil {
+			return err
+		}
+	}
+	return s.repo.DeleteDocument(doc.ID)
+}
+
+// Open - содержимое документа для просмотра агентом
+func (s *DocumentService) Open(ctx context.Context, doc *models.Document) (io.ReadCloser, error) {
+	if doc.StorageKey == "" {
+		return nil, storage.ErrNotFound
+	}
+	return s.store.Open(ctx, doc.StorageKey)
+}
+
+// newStorageKey - случайный ключ, чтобы имя файла клиента не попадало в путь
+func newStorageKey(userID uint, ext string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("documents/%d/%s%s", userID, hex.EncodeToString(buf), ext), nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"ac-ai/internal/money"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// FXRateProvider - источник курсов к базовой валюте (money.BaseCurrency).
+// Реализации: FileFXRates и repository.FXRateRepository
+type FXRateProvider interface {
+	// RateToBase - сколько единиц базовой валюты стоит одна единица currency
+	RateToBase(ctx context.Context, currency money.Currency) (money.Rate, error)
+}
+
+// ToBase переводит сумму в базовую валюту; курс базовой валюты к себе не запрашивается
+func ToBase(ctx context.Context, fx FXRateProvider, m money.Money) (money.Amount, error) {
+	rate, err := rateToBase(ctx, fx, m.Currency)
+	if err != nil {
+		return 0, err
+	}
+	return rate.ToBase(m.Amount), nil
+}
+
+// FromBase переводит сумму из базовой валюты в currency
+func FromBase(ctx context.Context, fx FXRateProvider, amount money.Amount, currency money.Currency) (money.Amount, error) {
+	rate, err := rateToBase(ctx, fx, currency)
+	if err != nil {
+		return 0, err
+	}
+	return rate.FromBase(amount), nil
+}
+
+func rateToBase(ctx context.Context, fx FXRateProvider, currency money.Currency) (money.Rate, error) {
+	if currency == money.BaseCurrency {
+		return money.One, nil
+	}
+	rate, err := fx.RateToBase(ctx, currency)
+	if err != nil {
+		return money.Rate{}, err
+	}
+	if !rate.Valid() {
+		return money.Rate{}, fmt.Errorf("%w: %s", money.ErrRateNotFound, currency)
+	}
+	return rate, nil
+}
+
+// FileFXRates - курсы из JSON-файла вида {"USD": "470.25", "RUB": "5.12"}.
+// Файл перечитывается при изменении, поэтому курсы можно обновлять без рестарта
+type FileFXRates struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	rates   map[money.Currency]money.Rate
+}
+
+func NewFileFXRates(path string) *FileFXRates {
+	return &FileFXRates{path: path}
+}
+
+func (f *FileFXRates) RateToBase(_ context.Context, currency money.Currency) (money.Rate, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.reload(); err != nil {
+		// Оставляем последние прочитанные курсы: битый файл не должен ронять скоринг
+		slog.Error("Failed to load FX rates", slog.String("path", f.path), slog.Any("error", err))
+	}
+
+	rate, ok := f.rates[currency]
+	if !ok {
+		return money.Rate{}, fmt.Errorf("%w: %s", money.ErrRateNotFound, currency)
+	}
+	return rate, nil
+}
+
+func (f *FileFXRates) reload() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return err
+	}
+	if f.rates != nil && info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	rates := make(map[money.Currency]money.Rate, len(raw))
+	for code, value := range raw {
+		currency, err := money.ParseCurrency(code)
+		if err != nil {
+			return err
+		}
+		rate, err := money.ParseRate(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", code, err)
+		}
+		rates[currency] = rate
+	}
+
+	f.rates = rates
+	f.modTime = info.ModTime()
+	return nil
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"ac-ai/internal/repository"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// StartInfoRequestExpiry - фоновая задача: отклоняет заявки, по которым
+// клиент не ответил на запрос агента в срок. Работает до отмены ctx
+func StartInfoRequestExpiry(ctx context.Context, repo *repository.ApplicationRepository, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				denied, err := repo.ExpireInfoRequests(now)
+				if err != nil {
+					slog.ErrorContext(ctx, "Failed to expire info requests", slog.Any("error", err))
+					continue
+				}
+				if denied > 0 {
+					slog.InfoContext(ctx, "Denied applications with expired info requests", slog.Int("count", denied))
+				}
+			}
+		}
+	}()
+}
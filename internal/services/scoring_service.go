@@ -2,6 +2,8 @@ package services
 
 import (
 	"ac-ai/internal/models"
+	"ac-ai/internal/money"
+	"context"
 )
 
 // ** НОВОЕ ПОЛЕ **
@@ -10,25 +12,61 @@ type ColdScoreResult struct {
 	TotalScore           int
 	Decision             string // "APPROVED", "DENIED", "MANUAL_REVIEW"
 	DtiRatio             float64
-	RecommendedMaxAmount float64 // Максимальная сумма, которую мы можем рекомендовать
-	RequestedAmount      float64
+	RecommendedMaxAmount money.Amount // Максимальная сумма, которую мы можем рекомендовать
+	RequestedAmount      money.Amount
+	Currency             money.Currency // Валюта кредита (в ней же RequestedAmount и RecommendedMaxAmount)
 	Recommendations      []string
+
+	// Те же суммы в базовой валюте - для сохранения заявки и статистики
+	RequestedAmountBase      money.Amount `json:"-"`
+	RecommendedMaxAmountBase money.Amount `json:"-"`
 }
 
-// Константа для "идеальной" долговой нагрузки (например, 40%)
-const maxSafeDTI = 0.40
+// Константа для "идеальной" долговой нагрузки (40%). Денежные расчеты идут
+// в целых тиынах, поэтому доля задана процентами.
+const maxSafeDTIPercent = 40
 
 // Срок кредита по умолчанию для расчета (60 мес = 5 лет)
 const defaultLoanTermMonths = 60
 
-func CalculateColdScore(profile *models.FinancialProfile, requestedAmount float64) *ColdScoreResult {
+// Валютный риск: если кредит не в валюте дохода, платеж считаем так, будто
+// валюта дохода уже ослабла на fxStressPercent, и дополнительно снижаем балл
+const (
+	fxStressPercent = 30
+	fxRiskPenalty   = 100
+)
+
+// CalculateColdScore - скоринг в базовой валюте: доход, платежи и запрошенная сумма
+// сначала переводятся по курсам fx, рекомендуемая сумма возвращается в валюте кредита
+func CalculateColdScore(ctx context.Context, fx FXRateProvider, profile *models.FinancialProfile, requested money.Money) (*ColdScoreResult, error) {
 	baseScore := 0
 	recommendations := []string{}
 
+	if requested.Currency == "" {
+		requested.Currency = money.BaseCurrency
+	}
+	incomeCurrency := profile.Currency
+	if incomeCurrency == "" {
+		incomeCurrency = money.BaseCurrency
+	}
+	income, err := ToBase(ctx, fx, money.New(profile.Income, incomeCurrency))
+	if err != nil {
+		return nil, err
+	}
+	monthlyPayments, err := ToBase(ctx, fx, money.New(profile.MonthlyPayments, incomeCurrency))
+	if err != nil {
+		return nil, err
+	}
+	requestedAmount, err := ToBase(ctx, fx, requested)
+	if err != nil {
+		return nil, err
+	}
+	fxExposed := requested.Currency != incomeCurrency
+
 	// --- ** НОВАЯ ЛОГИКА ** ---
 	// Рассчитываем, сколько пользователь может платить в месяц
-	maxTotalMonthlyPayment := profile.Income * maxSafeDTI
-	availableForNewPayment := maxTotalMonthlyPayment - profile.MonthlyPayments
+	maxTotalMonthlyPayment := income.MulRat(maxSafeDTIPercent, 100)
+	availableForNewPayment := maxTotalMonthlyPayment - monthlyPayments
 
 	// Если он уже тратит слишком много, он не может позволить себе новый кредит
 	if availableForNewPayment < 0 {
@@ -37,17 +75,23 @@ func CalculateColdScore(profile *models.FinancialProfile, requestedAmount float6
 
 	// Рассчитываем максимальную сумму, которую он может взять
 	// (Это обратный расчет от ежемесячного платежа)
-	recommendedMaxAmount := availableForNewPayment * defaultLoanTermMonths
+	recommendedMaxAmount := availableForNewPayment.MulRat(defaultLoanTermMonths, 1)
+	if fxExposed {
+		recommendedMaxAmount = recommendedMaxAmount.MulRat(100, 100+fxStressPercent)
+	}
 
 	// --- Конец новой логики ---
 
 	// 1. DTI (Долговая нагрузка)
 	var dti float64
-	newMonthlyPayment := requestedAmount / defaultLoanTermMonths
-	totalPayments := profile.MonthlyPayments + newMonthlyPayment
+	newMonthlyPayment := requestedAmount.MulRat(1, defaultLoanTermMonths)
+	if fxExposed {
+		newMonthlyPayment = newMonthlyPayment.MulRat(100+fxStressPercent, 100)
+	}
+	totalPayments := monthlyPayments + newMonthlyPayment
 
-	if profile.Income > 0 {
-		dti = totalPayments / profile.Income
+	if income > 0 {
+		dti = money.Ratio(totalPayments, income)
 	} else {
 		dti = 1.0 // Плохой DTI, если доход 0
 	}
@@ -87,7 +131,7 @@ func CalculateColdScore(profile *models.FinancialProfile, requestedAmount float6
 	// 4. Проверка на СВЕРХ-сумму
 	// Если запрошенная сумма (50 млрд) В РАЗЫ больше, чем мы можем дать,
 	// это гарантированный отказ или ручная проверка.
-	if requestedAmount > recommendedMaxAmount*1.5 { // Если просят на 50% больше, чем можно
+	if requestedAmount > recommendedMaxAmount.MulRat(3, 2) { // Если просят на 50% больше, чем можно
 		// Если DTI был в порядке (например, 20 млн / 60 = высокий платеж),
 		// но РЕКОМЕНДУЕМАЯ сумма (например, 5 млн) намного ниже,
 		// мы принудительно снижаем балл и меняем решение.
@@ -95,6 +139,12 @@ func CalculateColdScore(profile *models.FinancialProfile, requestedAmount float6
 		recommendations = append(recommendations, "Запрошенная сумма значительно превышает ваши финансовые возможности.")
 	}
 
+	// 5. Валютный риск
+	if fxExposed {
+		baseScore -= fxRiskPenalty
+		recommendations = append(recommendations, "Валюта кредита отличается от валюты дохода - это валютный риск.")
+	}
+
 	var decision string
 	if baseScore < 400 {
 		decision = "DENIED"
@@ -110,12 +160,20 @@ func CalculateColdScore(profile *models.FinancialProfile, requestedAmount float6
 		decision = "DENIED"
 	}
 
-	return &ColdScoreResult{
-		TotalScore:           baseScore,
-		Decision:             decision,
-		DtiRatio:             dti,                  // ** Добавили **
-		RecommendedMaxAmount: recommendedMaxAmount, // ** Добавили **
-		RequestedAmount:      requestedAmount,
-		Recommendations:      recommendations,
+	recommendedInLoanCurrency, err := FromBase(ctx, fx, recommendedMaxAmount, requested.Currency)
+	if err != nil {
+		return nil, err
 	}
+
+	return &ColdScoreResult{
+		TotalScore:               baseScore,
+		Decision:                 decision,
+		DtiRatio:                 dti,                       // ** Добавили **
+		RecommendedMaxAmount:     recommendedInLoanCurrency, // ** Добавили **
+		RequestedAmount:          requested.Amount,
+		Currency:                 requested.Currency,
+		Recommendations:          recommendations,
+		RequestedAmountBase:      requestedAmount,
+		RecommendedMaxAmountBase: recommendedMaxAmount,
+	}, nil
 }
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"ac-ai/internal/lockout"
+	"ac-ai/internal/repository"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// StartTokenCleanup - фоновая задача: удаляет истекшие refresh-токены, записи
+// списка отзыва access-токенов и устаревшие счетчики неудачных входов (throttles,
+// если LOGIN_LOCKOUT_STORE=postgres; иначе nil), чтобы таблицы не росли бесконечно.
+// window - окно обнуления счетчика. Работает до отмены ctx
+func StartTokenCleanup(ctx context.Context, repo *repository.TokenRepository, throttles *lockout.PostgresStore, window, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				purgeExpiredTokens(ctx, repo, now)
+				purgeLoginThrottles(ctx, throttles, now.Add(-window))
+			}
+		}
+	}()
+}
+
+func purgeExpiredTokens(ctx context.Context, repo *repository.TokenRepository, now time.Time) {
+	deleted, err := repo.PurgeExpired(now)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to purge expired tokens", slog.Any("error", err))
+		return
+	}
+	if deleted > 0 {
+		slog.InfoContext(ctx, "Purged expired tokens", slog.Int64("count", deleted))
+	}
+}
+
+func purgeLoginThrottles(ctx context.Context, throttles *lockout.PostgresStore, resetBefore time.Time) {
+	if throttles == nil {
+		return
+	}
+	deleted, err := throttles.PurgeStale(resetBefore)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to purge login throttles", slog.Any("error", err))
+		return
+	}
+	if deleted > 0 {
+		slog.InfoContext(ctx, "Purged login throttles", slog.Int64("count", deleted))
+	}
+}
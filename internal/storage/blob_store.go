@@ -0,0 +1,18 @@
+// internal/storage/blob_store.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound - объекта с таким ключом нет
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore - хранилище файлов. Локальная ФС сейчас, S3-совместимое - потом
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
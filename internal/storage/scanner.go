@@ -0,0 +1,24 @@
+// internal/storage/scanner.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrInfected - сканер нашел вредоносное содержимое
+var ErrInfected = errors.New("file is infected")
+
+// VirusScanner - хук для проверки загружаемых файлов (ClamAV и т.п.).
+// Возвращает ErrInfected для зараженных файлов, любую другую ошибку - если проверить не удалось
+type VirusScanner interface {
+	Scan(ctx context.Context, r io.Reader) error
+}
+
+// NoopScanner - пропускает все файлы (для локальной разработки)
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(ctx context.Context, r io.Reader) error {
+	return nil
+}
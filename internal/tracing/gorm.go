@@ -0,0 +1,79 @@
+// internal/tracing/gorm.go
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// GormPlugin - спан на каждый запрос GORM. Спан создается, только если в
+// контексте запроса (db.WithContext) уже есть родитель: фоновые запросы без
+// трассы не порождают тысячи корневых спанов
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string { return "tracing" }
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", beforeQuery("insert")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", afterQuery),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", beforeQuery("select")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", afterQuery),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", beforeQuery("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", afterQuery),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", beforeQuery("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", afterQuery),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", beforeQuery("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", afterQuery),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", beforeQuery("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", afterQuery),
+	)
+}
+
+func beforeQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanContextFromContext(ctx).IsValid() {
+			return
+		}
+		name := "db." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		ctx, span := Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system", "postgresql"), attribute.String("db.operation", operation)),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func afterQuery(db *gorm.DB) {
+	v, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := v.(trace.Span)
+
+	// Текст SQL с плейсхолдерами, без значений параметров: в них персональные данные
+	if db.Statement.Table != "" {
+		span.SetAttributes(attribute.String("db.sql.table", db.Statement.Table))
+	}
+	span.SetAttributes(
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+	span.End()
+}
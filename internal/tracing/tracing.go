@@ -0,0 +1,74 @@
+// internal/tracing/tracing.go
+package tracing
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Имя инструментирующей библиотеки в спанах (otel.scope.name)
+const instrumentationName = "ac-ai"
+
+type Options struct {
+	ServiceName string
+	// Доля корневых трасс; входящий traceparent решает за нас (ParentBased)
+	SampleRatio float64
+	// nil - спаны создаются (есть trace_id в логах), но никуда не отправляются
+	Exporter sdktrace.SpanExporter
+}
+
+// Setup - глобальный TracerProvider и W3C propagator (traceparent/tracestate).
+// Провайдер возвращается для Shutdown: он досылает накопленные спаны
+func Setup(opts Options) *sdktrace.TracerProvider {
+	res, err := resource.Merge(resource.Default(),
+		resource.NewSchemaless(attribute.String("service.name", opts.ServiceName)))
+	if err != nil {
+		res = resource.Default()
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	}
+	if opts.Exporter != nil {
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(opts.Exporter))
+	}
+	tp := sdktrace.NewTracerProvider(providerOpts...)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		slog.Warn("Tracing error", slog.Any("error", err))
+	}))
+	return tp
+}
+
+// NewOTLPExporter - OTLP/HTTP (POST <endpoint>/v1/traces).
+// headers - "key=value,key2=value2", как в OTEL_EXPORTER_OTLP_HEADERS
+func NewOTLPExporter(ctx context.Context, endpoint, headers string) (sdktrace.SpanExporter, error) {
+	parsed := map[string]string{}
+	for _, pair := range strings.Split(headers, ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok {
+			parsed[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpointURL(strings.TrimRight(endpoint, "/")+"/v1/traces"),
+		otlptracehttp.WithHeaders(parsed),
+	)
+}
+
+// Tracer - трейсер приложения. Берется из глобального провайдера при каждом
+// вызове, поэтому работает и до Setup (no-op), и в тестах со своим провайдером
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}